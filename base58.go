@@ -0,0 +1,127 @@
+package tezos
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() (idx [256]int) {
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i, c := range base58Alphabet {
+		idx[c] = i
+	}
+	return
+}()
+
+// Base58Check prefixes of the Tezos hashes and keys
+var (
+	prefixScriptExpr      = []byte{13, 44, 64, 27}   // expr
+	prefixEd25519PK       = []byte{13, 15, 37, 217}  // edpk
+	prefixSecp256k1PK     = []byte{3, 254, 226, 86}  // sppk
+	prefixP256PK          = []byte{3, 178, 139, 127} // p2pk
+	prefixEd25519PKHash   = []byte{6, 161, 159}      // tz1
+	prefixSecp256k1PKHash = []byte{6, 161, 161}      // tz2
+	prefixP256PKHash      = []byte{6, 161, 164}      // tz3
+	prefixContractHash    = []byte{2, 90, 121}       // KT1
+	prefixBlockHash       = []byte{1, 52}            // B
+	prefixOperationHash   = []byte{5, 116}           // o
+	prefixProtocolHash    = []byte{2, 170}           // P
+	prefixChainID         = []byte{87, 82, 0}        // Net
+
+	prefixEd25519Signature   = []byte{9, 245, 205, 134, 18} // edsig
+	prefixSecp256k1Signature = []byte{13, 115, 101, 19, 63} // spsig1
+	prefixP256Signature      = []byte{54, 240, 44, 52}      // p2sig
+	prefixGenericSignature   = []byte{4, 130, 43}           // sig
+)
+
+func base58Encode(data []byte) string {
+	var zeros int
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	var (
+		n   = new(big.Int).SetBytes(data)
+		mod = new(big.Int)
+		rad = big.NewInt(58)
+		out []byte
+	)
+	for n.Sign() != 0 {
+		n.QuoRem(n, rad, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	var zeros int
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	var (
+		n   = new(big.Int)
+		rad = big.NewInt(58)
+	)
+	for i := 0; i < len(s); i++ {
+		d := base58Index[s[i]]
+		if d < 0 {
+			return nil, fmt.Errorf("tezos: invalid base58 character %q", s[i])
+		}
+		n.Mul(n, rad)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+func base58CheckEncode(prefix, payload []byte) string {
+	data := make([]byte, 0, len(prefix)+len(payload)+4)
+	data = append(data, prefix...)
+	data = append(data, payload...)
+	sum := doubleSHA256(data)
+	return base58Encode(append(data, sum[:4]...))
+}
+
+var errBase58Checksum = errors.New("tezos: invalid base58 checksum")
+
+// base58CheckDecode decodes a Base58Check string and strips the expected prefix
+func base58CheckDecode(s string, prefix []byte) ([]byte, error) {
+	data, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, errBase58Checksum
+	}
+
+	payload, sum := data[:len(data)-4], data[len(data)-4:]
+	expected := doubleSHA256(payload)
+	if !bytes.Equal(sum, expected[:4]) {
+		return nil, errBase58Checksum
+	}
+
+	if !bytes.HasPrefix(payload, prefix) {
+		return nil, fmt.Errorf("tezos: unexpected base58 prefix: %q", s)
+	}
+
+	return payload[len(prefix):], nil
+}
+
+func doubleSHA256(data []byte) [32]byte {
+	sum := sha256.Sum256(data)
+	return sha256.Sum256(sum[:])
+}
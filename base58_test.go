@@ -0,0 +1,36 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase58Check(t *testing.T) {
+	tests := []struct {
+		src     string
+		prefix  []byte
+		payload string
+		errMsg  string
+	}{
+		{src: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", prefix: []byte{6, 161, 159}},
+		{src: "NetXdQprcVkpaWU", prefix: []byte{87, 82, 0}, payload: "7a06a770"},
+		{src: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSy", prefix: []byte{6, 161, 159}, errMsg: "tezos: invalid base58 checksum"},
+		{src: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZS0", prefix: []byte{6, 161, 159}, errMsg: "tezos: invalid base58 character '0'"},
+		{src: "NetXdQprcVkpaWU", prefix: []byte{6, 161, 159}, errMsg: `tezos: unexpected base58 prefix: "NetXdQprcVkpaWU"`},
+	}
+
+	for _, test := range tests {
+		payload, err := base58CheckDecode(test.src, test.prefix)
+		if test.errMsg != "" {
+			require.EqualError(t, err, test.errMsg)
+			continue
+		}
+		require.NoError(t, err)
+		if test.payload != "" {
+			require.Equal(t, test.payload, hex.EncodeToString(payload))
+		}
+		require.Equal(t, test.src, base58CheckEncode(test.prefix, payload))
+	}
+}
@@ -1,9 +1,11 @@
 package tezos
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"time"
 )
 
@@ -20,6 +22,29 @@ func (hb *HexBytes) UnmarshalText(data []byte) error {
 	return nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler. JSON null yields a nil slice.
+func (hb *HexBytes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*hb = nil
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	return hb.UnmarshalText([]byte(s))
+}
+
+// MarshalJSON implements json.Marshaler
+func (hb HexBytes) MarshalJSON() ([]byte, error) {
+	if hb == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(hex.EncodeToString(hb))
+}
+
 // BlockInfo holds information about block returned by monitor heads endpoint
 type BlockInfo struct {
 	Hash           string     `json:"hash" yaml:"hash"`
@@ -34,6 +59,12 @@ type BlockInfo struct {
 	ProtocolData   string     `json:"protocol_data" yaml:"protocol_data"`
 }
 
+// BlockProtocols holds the protocol of a block and the protocol of its successor. They differ on the last block before a migration
+type BlockProtocols struct {
+	Protocol     string `json:"protocol" yaml:"protocol"`
+	NextProtocol string `json:"next_protocol" yaml:"next_protocol"`
+}
+
 // RawBlockHeader is a part of the Tezos block data
 type RawBlockHeader struct {
 	Level            int        `json:"level" yaml:"level"`
@@ -50,6 +81,66 @@ type RawBlockHeader struct {
 	Signature        string     `json:"signature" yaml:"signature"`
 }
 
+// FitnessValue returns the fitness as a single integer comparable the same way as fitness lists are.
+// Emmy fitness components are concatenated in order. Tenderbake fitness has an optional locked round
+// component so components are widened to a fixed size first: a missing locked round sorts before any present one.
+func (h *RawBlockHeader) FitnessValue() (*big.Int, error) {
+	if len(h.Fitness) == 0 {
+		return nil, fmt.Errorf("tezos: empty fitness")
+	}
+
+	var buf []byte
+	if len(h.Fitness[0]) == 1 && h.Fitness[0][0] >= 2 {
+		// Tenderbake fitness: version, level, locked round, predecessor round, round
+		if len(h.Fitness) != 5 {
+			return nil, fmt.Errorf("tezos: invalid Tenderbake fitness length: %d", len(h.Fitness))
+		}
+		for i, f := range h.Fitness[1:] {
+			// The locked round is empty if there is none
+			if len(f) != 4 && !(i == 1 && len(f) == 0) {
+				return nil, fmt.Errorf("tezos: invalid Tenderbake fitness component length: %d", len(f))
+			}
+		}
+		buf = append(buf, h.Fitness[0]...)
+		buf = append(buf, h.Fitness[1]...)
+		if locked := h.Fitness[2]; len(locked) != 0 {
+			buf = append(append(buf, 1), locked...)
+		} else {
+			buf = append(buf, 0, 0, 0, 0, 0)
+		}
+		buf = append(buf, h.Fitness[3]...)
+		buf = append(buf, h.Fitness[4]...)
+	} else {
+		for _, f := range h.Fitness {
+			buf = append(buf, f...)
+		}
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// Round returns the Tenderbake round of the block which is the last component of the fitness.
+// Emmy blocks use Priority instead.
+func (h *RawBlockHeader) Round() (int, error) {
+	// Tenderbake fitness: version, level, locked round, predecessor round, round
+	if len(h.Fitness) != 5 || len(h.Fitness[0]) != 1 || h.Fitness[0][0] < 2 {
+		return 0, fmt.Errorf("tezos: not a Tenderbake fitness")
+	}
+	r := h.Fitness[4]
+	if len(r) != 4 {
+		return 0, fmt.Errorf("tezos: invalid round length: %d", len(r))
+	}
+	return int(int32(binary.BigEndian.Uint32(r))), nil
+}
+
+// BlockHeader is a block header returned by the header endpoint
+type BlockHeader struct {
+	Protocol       string `json:"protocol" yaml:"protocol"`
+	ChainID        string `json:"chain_id" yaml:"chain_id"`
+	Hash           string `json:"hash" yaml:"hash"`
+	RawBlockHeader `yaml:",inline"`
+}
+
 // TestChainStatus is a variable structure depending on the Status field
 type TestChainStatus interface {
 	TestChainStatus() string
@@ -103,6 +194,15 @@ type BlockHeaderMetadataLevel struct {
 	ExpectedCommitment   bool `json:"expected_commitment" yaml:"expected_commitment"`
 }
 
+// LevelInfo is the level_info part of BlockHeaderMetadata used by newer protocols instead of level
+type LevelInfo struct {
+	Level              int  `json:"level" yaml:"level"`
+	LevelPosition      int  `json:"level_position" yaml:"level_position"`
+	Cycle              int  `json:"cycle" yaml:"cycle"`
+	CyclePosition      int  `json:"cycle_position" yaml:"cycle_position"`
+	ExpectedCommitment bool `json:"expected_commitment" yaml:"expected_commitment"`
+}
+
 // BlockHeaderMetadata is a part of the Tezos block data
 type BlockHeaderMetadata struct {
 	Protocol               string                    `json:"protocol" yaml:"protocol"`
@@ -113,7 +213,8 @@ type BlockHeaderMetadata struct {
 	MaxBlockHeaderLength   int                       `json:"max_block_header_length" yaml:"max_block_header_length"`
 	MaxOperationListLength []*MaxOperationListLength `json:"max_operation_list_length" yaml:"max_operation_list_length"`
 	Baker                  string                    `json:"baker" yaml:"baker"`
-	Level                  BlockHeaderMetadataLevel  `json:"level" yaml:"level"`
+	Proposer               string                    `json:"proposer,omitempty" yaml:"proposer,omitempty"` // Tenderbake
+	Level                  BlockHeaderMetadataLevel  `json:"level" yaml:"level"`                           // populated from level_info too
 	VotingPeriodKind       string                    `json:"voting_period_kind" yaml:"voting_period_kind"`
 	NonceHash              string                    `json:"nonce_hash" yaml:"nonce_hash"`
 	ConsumedGas            *BigInt                   `json:"consumed_gas" yaml:"consumed_gas"`
@@ -157,12 +258,27 @@ func (bhm *BlockHeaderMetadata) UnmarshalJSON(data []byte) error {
 
 	var tmp struct {
 		TestChainStatus json.RawMessage `json:"test_chain_status" yaml:"test_chain_status"`
+		LevelInfo       *LevelInfo      `json:"level_info"`
 	}
 
 	if err := json.Unmarshal(data, &tmp); err != nil {
 		return err
 	}
 
+	if li := tmp.LevelInfo; li != nil {
+		bhm.Level = BlockHeaderMetadataLevel{
+			Level:              li.Level,
+			LevelPosition:      li.LevelPosition,
+			Cycle:              li.Cycle,
+			CyclePosition:      li.CyclePosition,
+			ExpectedCommitment: li.ExpectedCommitment,
+		}
+	}
+
+	if len(tmp.TestChainStatus) == 0 {
+		return nil
+	}
+
 	tcs, err := unmarshalTestChainStatus(tmp.TestChainStatus)
 	if err != nil {
 		return err
@@ -173,6 +289,11 @@ func (bhm *BlockHeaderMetadata) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// PeriodKind returns the voting period kind
+func (bhm *BlockHeaderMetadata) PeriodKind() VotingPeriodKind {
+	return VotingPeriodKind(bhm.VotingPeriodKind)
+}
+
 // Block holds information about a Tezos block
 type Block struct {
 	Protocol   string              `json:"protocol" yaml:"protocol"`
@@ -182,3 +303,58 @@ type Block struct {
 	Metadata   BlockHeaderMetadata `json:"metadata" yaml:"metadata"`
 	Operations [][]*Operation      `json:"operations" yaml:"operations"`
 }
+
+// Baker returns the delegate who baked the block
+func (b *Block) Baker() string {
+	if b.Metadata.Baker != "" {
+		return b.Metadata.Baker
+	}
+	return b.Metadata.Proposer
+}
+
+// Proposer returns the delegate who proposed the block payload. Pre-Tenderbake it's the baker.
+func (b *Block) Proposer() string {
+	if b.Metadata.Proposer != "" {
+		return b.Metadata.Proposer
+	}
+	return b.Metadata.Baker
+}
+
+// IsMigration returns true if the block is the last one of its protocol and the protocol migration happens after it
+func (b *Block) IsMigration() bool {
+	return b.Metadata.NextProtocol != "" && b.Metadata.Protocol != b.Metadata.NextProtocol
+}
+
+// MigrationBalanceUpdates returns block balance updates caused by the protocol migration
+func (b *Block) MigrationBalanceUpdates() BalanceUpdates {
+	var res BalanceUpdates
+	for _, u := range b.Metadata.BalanceUpdates {
+		if u.BalanceUpdateOrigin() == "migration" {
+			res = append(res, u)
+		}
+	}
+	return res
+}
+
+// FailedOperationErrorIDs returns distinct error ids of all failed and backtracked operation results in the block
+func (b *Block) FailedOperationErrorIDs() []string {
+	var res []string
+	seen := make(map[string]struct{})
+	for _, list := range b.Operations {
+		for _, op := range list {
+			for _, r := range op.operationResults() {
+				if r.OperationResultStatus() == "applied" {
+					continue
+				}
+				for _, e := range r.OperationResultErrors() {
+					id := e.ErrorID()
+					if _, ok := seen[id]; !ok {
+						seen[id] = struct{}{}
+						res = append(res, id)
+					}
+				}
+			}
+		}
+	}
+	return res
+}
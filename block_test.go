@@ -0,0 +1,201 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexBytes(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected HexBytes
+		dst      string
+		errMsg   string
+	}{
+		{src: `null`, expected: nil, dst: `null`},
+		{src: `""`, expected: HexBytes{}, dst: `""`},
+		{src: `"7d949582fe024862"`, expected: HexBytes{0x7d, 0x94, 0x95, 0x82, 0xfe, 0x02, 0x48, 0x62}, dst: `"7d949582fe024862"`},
+		{src: `"7d9"`, errMsg: "encoding/hex: odd length hex string"},
+		{src: `"xx"`, errMsg: "encoding/hex: invalid byte: U+0078 'x'"},
+		{src: `12`, errMsg: "json: cannot unmarshal number into Go value of type string"},
+	}
+
+	for _, test := range tests {
+		var v HexBytes
+		err := json.Unmarshal([]byte(test.src), &v)
+		if test.errMsg != "" {
+			require.EqualError(t, err, test.errMsg)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, test.expected, v)
+
+		buf, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.Equal(t, test.dst, string(buf))
+	}
+}
+
+func TestBlockBakerProposer(t *testing.T) {
+	t.Run("Emmy", func(t *testing.T) {
+		var b Block
+		require.NoError(t, json.Unmarshal([]byte(`{"header":{"level":219133},"metadata":{"baker":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}}`), &b))
+		require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", b.Baker())
+		require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", b.Proposer())
+	})
+
+	t.Run("Tenderbake", func(t *testing.T) {
+		var b Block
+		require.NoError(t, json.Unmarshal([]byte(`{"header":{"level":2244609},"metadata":{"proposer":"tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN","baker":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}}`), &b))
+		require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", b.Baker())
+		require.Equal(t, "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", b.Proposer())
+	})
+}
+
+func TestBlockHeaderMetadataLevel(t *testing.T) {
+	var old BlockHeaderMetadata
+	require.NoError(t, json.Unmarshal([]byte(`{"level":{"level":219133,"level_position":219132,"cycle":53,"cycle_position":2045,"voting_period":6,"voting_period_position":22524,"expected_commitment":false}}`), &old))
+	require.Equal(t, BlockHeaderMetadataLevel{Level: 219133, LevelPosition: 219132, Cycle: 53, CyclePosition: 2045, VotingPeriod: 6, VotingPeriodPosition: 22524}, old.Level)
+
+	var info BlockHeaderMetadata
+	require.NoError(t, json.Unmarshal([]byte(`{"level_info":{"level":1466368,"level_position":1466367,"cycle":358,"cycle_position":4095,"expected_commitment":true}}`), &info))
+	require.Equal(t, BlockHeaderMetadataLevel{Level: 1466368, LevelPosition: 1466367, Cycle: 358, CyclePosition: 4095, ExpectedCommitment: true}, info.Level)
+}
+
+func TestMigrationBlock(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/chains/migration_block.json")
+	require.NoError(t, err)
+
+	var block Block
+	require.NoError(t, json.Unmarshal(data, &block))
+	require.True(t, block.IsMigration())
+	require.Equal(t, BalanceUpdates{
+		&ContractBalanceUpdate{
+			GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: 500000000, Origin: "migration"},
+			Contract:             "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43",
+		},
+	}, block.MigrationBalanceUpdates())
+
+	data, err = ioutil.ReadFile("fixtures/chains/block.json")
+	require.NoError(t, err)
+
+	block = Block{}
+	require.NoError(t, json.Unmarshal(data, &block))
+	require.False(t, block.IsMigration())
+	require.Empty(t, block.MigrationBalanceUpdates())
+}
+
+func TestFailedOperationErrorIDs(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/chains/failed_operations_block.json")
+	require.NoError(t, err)
+
+	var block Block
+	require.NoError(t, json.Unmarshal(data, &block))
+	require.Equal(t, []string{
+		"proto.005-PsBabyM1.michelson_v1.runtime_error",
+		"proto.005-PsBabyM1.michelson_v1.script_rejected",
+		"proto.005-PsBabyM1.delegate.unchanged",
+	}, block.FailedOperationErrorIDs())
+
+	data, err = ioutil.ReadFile("fixtures/chains/block.json")
+	require.NoError(t, err)
+
+	block = Block{}
+	require.NoError(t, json.Unmarshal(data, &block))
+	require.Empty(t, block.FailedOperationErrorIDs())
+}
+
+func TestFitnessValue(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/double_baking_evidence.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Len(t, op.Contents, 1)
+
+	ev, ok := op.Contents[0].(*DoubleBakingEvidenceOperationElem)
+	require.True(t, ok)
+
+	f1, err := ev.BlockHeader1.FitnessValue()
+	require.NoError(t, err)
+	f2, err := ev.BlockHeader2.FitnessValue()
+	require.NoError(t, err)
+
+	require.Equal(t, big.NewInt(0x1788f6), f1)
+	require.Equal(t, 1, f1.Cmp(f2))
+
+	_, err = (&RawBlockHeader{}).FitnessValue()
+	require.Error(t, err)
+
+	// Tenderbake
+	tenderbake := func(fitness ...string) *big.Int {
+		var h RawBlockHeader
+		for _, f := range fitness {
+			b, err := hex.DecodeString(f)
+			require.NoError(t, err)
+			h.Fitness = append(h.Fitness, b)
+		}
+		v, err := h.FitnessValue()
+		require.NoError(t, err)
+		return v
+	}
+	unlocked := tenderbake("02", "00001001", "", "ffffffff", "00000000")
+	locked := tenderbake("02", "00001000", "00000002", "ffffffff", "00000000")
+	lockedHigher := tenderbake("02", "00001000", "00000003", "ffffffff", "00000000")
+	unlockedSameLevel := tenderbake("02", "00001000", "", "ffffffff", "00000001")
+	// Higher level wins regardless of the locked round
+	require.Equal(t, 1, unlocked.Cmp(locked))
+	require.Equal(t, 1, lockedHigher.Cmp(locked))
+	require.Equal(t, 1, locked.Cmp(unlockedSameLevel))
+
+	_, err = (&RawBlockHeader{Fitness: []HexBytes{{2}, {0, 0, 0x10, 0}, {0}, {0, 0, 0, 0}, {0, 0, 0, 0}}}).FitnessValue()
+	require.Error(t, err)
+}
+
+func TestRound(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/block/tenderbake_header.json")
+	require.NoError(t, err)
+
+	var h BlockHeader
+	require.NoError(t, json.Unmarshal(data, &h))
+
+	round, err := h.Round()
+	require.NoError(t, err)
+	require.Equal(t, 2, round)
+
+	// Emmy fitness
+	emmy := RawBlockHeader{Fitness: []HexBytes{{0x00}, {0x00, 0x00, 0x00, 0x00, 0x00, 0x17, 0x88, 0xf6}}}
+	_, err = emmy.Round()
+	require.Error(t, err)
+}
+
+func TestBlockBalanceUpdates(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/chains/block.json")
+	require.NoError(t, err)
+
+	var block Block
+	require.NoError(t, json.Unmarshal(data, &block))
+
+	// Block metadata and operation metadata share the same balance update types
+	require.Equal(t, BalanceUpdates{
+		&ContractBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: -512000000}, Contract: "tz3gN8NTLNLJg5KRsUU47NHNVHbdhcFXjjaB"},
+		&FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 512000000}, Category: "deposits", Delegate: "tz3gN8NTLNLJg5KRsUU47NHNVHbdhcFXjjaB", Level: 106},
+	}, block.Metadata.BalanceUpdates)
+
+	var ops BalanceUpdates
+	for _, list := range block.Operations {
+		for _, op := range list {
+			ops = append(ops, op.balanceUpdates()...)
+		}
+	}
+	require.Equal(t, BalanceUpdates{
+		&ContractBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: -128000000}, Contract: "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq"},
+		&FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 128000000}, Category: "deposits", Delegate: "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", Level: 106},
+		&FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 2000000}, Category: "rewards", Delegate: "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", Level: 106},
+	}, ops)
+}
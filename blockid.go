@@ -0,0 +1,40 @@
+package tezos
+
+import (
+	"strconv"
+)
+
+// Block id helpers produce the path segments accepted by blockID arguments of Service methods,
+// e.g. "head", "head~5", a level or a hash.
+
+// BlockHead returns the id of the current head
+func BlockHead() string {
+	return "head"
+}
+
+// BlockLevel returns the id of the block at the given level
+func BlockLevel(n int32) string {
+	return strconv.FormatInt(int64(n), 10)
+}
+
+// BlockHash returns the id of the block with the given hash
+func BlockHash(h string) string {
+	return h
+}
+
+// BlockRelativeToHead returns the id of the block offset levels away from the current head.
+// Negative offset refers to an ancestor, e.g. BlockRelativeToHead(-5) is "head~5".
+func BlockRelativeToHead(offset int) string {
+	return BlockRelativeTo("head", offset)
+}
+
+// BlockRelativeTo returns the id of the block offset levels away from the given block
+func BlockRelativeTo(hash string, offset int) string {
+	switch {
+	case offset < 0:
+		return hash + "~" + strconv.Itoa(-offset)
+	case offset > 0:
+		return hash + "+" + strconv.Itoa(offset)
+	}
+	return hash
+}
@@ -0,0 +1,27 @@
+package tezos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockID(t *testing.T) {
+	cases := []struct {
+		id       string
+		expected string
+	}{
+		{id: BlockHead(), expected: "head"},
+		{id: BlockLevel(219133), expected: "219133"},
+		{id: BlockHash("BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm"), expected: "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm"},
+		{id: BlockRelativeToHead(-5), expected: "head~5"},
+		{id: BlockRelativeToHead(2), expected: "head+2"},
+		{id: BlockRelativeToHead(0), expected: "head"},
+		{id: BlockRelativeTo("BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", -1), expected: "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm~1"},
+		{id: BlockRelativeTo("BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", 3), expected: "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm+3"},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.expected, c.id)
+	}
+}
@@ -0,0 +1,148 @@
+package tezos
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// ManagerOptions holds optional fields of a manager operation. Nil values default to zero.
+type ManagerOptions struct {
+	Fee          *BigInt
+	GasLimit     *BigInt
+	StorageLimit *BigInt
+}
+
+// ManagerOption sets an optional field of a manager operation
+type ManagerOption func(*ManagerOptions)
+
+// WithFee sets the operation fee in mutez
+func WithFee(fee *BigInt) ManagerOption {
+	return func(o *ManagerOptions) { o.Fee = fee }
+}
+
+// WithGasLimit sets the operation gas limit
+func WithGasLimit(limit *BigInt) ManagerOption {
+	return func(o *ManagerOptions) { o.GasLimit = limit }
+}
+
+// WithStorageLimit sets the operation storage limit
+func WithStorageLimit(limit *BigInt) ManagerOption {
+	return func(o *ManagerOptions) { o.StorageLimit = limit }
+}
+
+func newManagerOptions(opts []ManagerOption) *ManagerOptions {
+	var o ManagerOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	for _, v := range []**BigInt{&o.Fee, &o.GasLimit, &o.StorageLimit} {
+		if *v == nil {
+			*v = &BigInt{}
+		}
+	}
+	return &o
+}
+
+// OperationBuilder constructs contents of a batch of manager operations
+type OperationBuilder struct {
+	counter  *BigInt
+	contents OperationElements
+}
+
+// NewOperationBuilder returns a new OperationBuilder. Operations are numbered sequentially starting with counter
+// which is usually the source's current counter plus one.
+func NewOperationBuilder(counter *BigInt) *OperationBuilder {
+	return &OperationBuilder{counter: counter}
+}
+
+// AddTransaction appends a transaction of amount mutez from source to dest
+func (b *OperationBuilder) AddTransaction(source, dest string, amount *BigInt, opts ...ManagerOption) *OperationBuilder {
+	o := newManagerOptions(opts)
+	if amount == nil {
+		amount = &BigInt{}
+	}
+	b.contents = append(b.contents, &TransactionOperationElem{
+		GenericOperationElem: GenericOperationElem{Kind: "transaction"},
+		Source:               source,
+		Fee:                  o.Fee,
+		GasLimit:             o.GasLimit,
+		StorageLimit:         o.StorageLimit,
+		Amount:               amount,
+		Destination:          dest,
+	})
+	return b
+}
+
+// AddReveal appends a reveal of the source's public key
+func (b *OperationBuilder) AddReveal(source, pubKey string, opts ...ManagerOption) *OperationBuilder {
+	o := newManagerOptions(opts)
+	b.contents = append(b.contents, &RevealOperationElem{
+		GenericOperationElem: GenericOperationElem{Kind: "reveal"},
+		Source:               source,
+		Fee:                  o.Fee,
+		GasLimit:             o.GasLimit,
+		StorageLimit:         o.StorageLimit,
+		PublicKey:            pubKey,
+	})
+	return b
+}
+
+// AddDelegation appends a delegation. Empty delegate withdraws the delegation.
+func (b *OperationBuilder) AddDelegation(source, delegate string, opts ...ManagerOption) *OperationBuilder {
+	o := newManagerOptions(opts)
+	b.contents = append(b.contents, &DelegationOperationElem{
+		GenericOperationElem: GenericOperationElem{Kind: "delegation"},
+		Source:               source,
+		Fee:                  o.Fee,
+		GasLimit:             o.GasLimit,
+		StorageLimit:         o.StorageLimit,
+		Delegate:             delegate,
+	})
+	return b
+}
+
+// Build returns the operation contents with counters filled in
+func (b *OperationBuilder) Build() OperationElements {
+	var counter big.Int
+	if b.counter != nil {
+		counter.Set(&b.counter.Int)
+	}
+
+	res := make(OperationElements, len(b.contents))
+	for i, el := range b.contents {
+		c := &BigInt{}
+		c.Int.Add(&counter, big.NewInt(int64(i)))
+
+		switch el := el.(type) {
+		case *TransactionOperationElem:
+			tmp := *el
+			tmp.Counter = c
+			res[i] = &tmp
+		case *RevealOperationElem:
+			tmp := *el
+			tmp.Counter = c
+			res[i] = &tmp
+		case *DelegationOperationElem:
+			tmp := *el
+			tmp.Counter = c
+			res[i] = &tmp
+		}
+	}
+	return res
+}
+
+// ForgeInput returns the request body of the forge RPC for the built contents
+// https://tezos.gitlab.io/mainnet/api/rpc.html#post-block-id-helpers-forge-operations
+func (b *OperationBuilder) ForgeInput(branch string) ([]byte, error) {
+	contents, err := b.Build().ForgeInput()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		Branch   string            `json:"branch"`
+		Contents []json.RawMessage `json:"contents"`
+	}{
+		Branch:   branch,
+		Contents: contents,
+	})
+}
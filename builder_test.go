@@ -0,0 +1,60 @@
+package tezos
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationBuilder(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/signed.json")
+	require.NoError(t, err)
+
+	var expected Operation
+	require.NoError(t, json.Unmarshal(data, &expected))
+
+	b := NewOperationBuilder(bigIntMust("30")).
+		AddReveal("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yav",
+			WithFee(bigIntMust("1269")), WithGasLimit(bigIntMust("10000"))).
+		AddTransaction("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", bigIntMust("1000000"),
+			WithFee(bigIntMust("1420")), WithGasLimit(bigIntMust("10307")), WithStorageLimit(bigIntMust("257"))).
+		AddDelegation("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+			WithFee(bigIntMust("1257")), WithGasLimit(bigIntMust("10000")))
+
+	op := Operation{
+		Branch:   expected.Branch,
+		Contents: b.Build(),
+	}
+
+	forged, err := op.Bytes()
+	require.NoError(t, err)
+	expectedForged, err := expected.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, expectedForged, forged)
+
+	// Builds are independent
+	contents := b.AddTransaction("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", nil).Build()
+	require.Len(t, contents, 4)
+	require.Equal(t, "30", op.Contents[0].(*RevealOperationElem).Counter.String())
+
+	tx := contents[3].(*TransactionOperationElem)
+	require.Equal(t, "33", tx.Counter.String())
+	for _, v := range []*BigInt{tx.Fee, tx.GasLimit, tx.StorageLimit, tx.Amount} {
+		require.Equal(t, "0", v.String())
+	}
+
+	// Forge RPC input
+	buf, err := b.ForgeInput(expected.Branch)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"branch": "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8",
+		"contents": [
+			{"kind": "reveal", "source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "fee": "1269", "counter": "30", "gas_limit": "10000", "storage_limit": "0", "public_key": "edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yav"},
+			{"kind": "transaction", "source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "fee": "1420", "counter": "31", "gas_limit": "10307", "storage_limit": "257", "amount": "1000000", "destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"},
+			{"kind": "delegation", "source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "fee": "1257", "counter": "32", "gas_limit": "10000", "storage_limit": "0", "delegate": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"},
+			{"kind": "transaction", "source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "fee": "0", "counter": "33", "gas_limit": "0", "storage_limit": "0", "amount": "0", "destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"}
+		]
+	}`, string(buf))
+}
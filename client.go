@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -65,12 +66,20 @@ func (c *RPCClient) NewRequest(ctx context.Context, method, urlStr string, body
 type RPCClient struct {
 	// Logger
 	Logger Logger
+	// Silent disables the fallback to the logrus standard logger if Logger is not set.
+	Silent bool
 	// HTTP transport used to communicate with the Tezos node API. Can be used for side effects.
 	Transport http.RoundTripper
 	// Base URL for API requests.
 	BaseURL *url.URL
 	// User agent name for client.
 	UserAgent string
+	// RequestTimeout limits the duration of non-streaming requests if non zero.
+	// Streaming requests (the ones which are being decoded into a channel) are long-lived by design and ignore it.
+	RequestTimeout time.Duration
+	// DecodeErrorWithBody makes non-streaming requests return DecodeError carrying the beginning of the response body
+	// if the response can't be decoded.
+	DecodeErrorWithBody bool
 }
 
 // NewRPCClient returns a new Tezos RPC client.
@@ -84,14 +93,82 @@ func NewRPCClient(baseURL string) (*RPCClient, error) {
 	}, nil
 }
 
+var silentLogger = &log.Logger{
+	Out:       ioutil.Discard,
+	Formatter: new(log.TextFormatter),
+	Hooks:     make(log.LevelHooks),
+	Level:     log.PanicLevel,
+}
+
 func (c *RPCClient) log() Logger {
 	if c.Logger != nil {
 		return c.Logger
 	}
+	if c.Silent {
+		return silentLogger
+	}
 	return log.StandardLogger()
 }
 
+// streamDecoder is implemented by values consuming the response body incrementally instead of decoding it at once
+type streamDecoder interface {
+	decodeStream(ctx context.Context, dec *json.Decoder) error
+}
+
+// ArrayStream makes Do decode a response consisting of a single JSON array element by element sending each one to Chan
+// as soon as it's decoded. Chan must be a channel of the array element type.
+// Unlike passing a channel to Do directly, which expects a stream of concatenated JSON values, the array is never buffered as a whole
+type ArrayStream struct {
+	Chan interface{}
+}
+
+func (a *ArrayStream) decodeStream(ctx context.Context, dec *json.Decoder) error {
+	ch := reflect.ValueOf(a.Chan)
+	if ch.Kind() != reflect.Chan || ch.Type().ChanDir()&reflect.SendDir == 0 {
+		return fmt.Errorf("tezos: ArrayStream needs a sendable channel, got %T", a.Chan)
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("tezos: JSON array expected, got %v", tok)
+	}
+
+	cases := []reflect.SelectCase{
+		reflect.SelectCase{
+			Dir:  reflect.SelectSend,
+			Chan: ch,
+		},
+		reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(ctx.Done()),
+		},
+	}
+
+	for dec.More() {
+		elem := reflect.New(ch.Type().Elem())
+		if err := dec.Decode(elem.Interface()); err != nil {
+			return err
+		}
+
+		cases[0].Send = elem.Elem()
+		if chosen, _, _ := reflect.Select(cases); chosen == 1 {
+			return ctx.Err()
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
 func (c *RPCClient) handleNormalResponse(ctx context.Context, resp *http.Response, v interface{}) error {
+	if sd, ok := v.(streamDecoder); ok {
+		dumpResponse(c.log(), log.DebugLevel, resp, false)
+		return sd.decodeStream(ctx, json.NewDecoder(resp.Body))
+	}
+
 	// Normal return
 	typ := reflect.TypeOf(v)
 
@@ -135,9 +212,22 @@ func (c *RPCClient) handleNormalResponse(ctx context.Context, resp *http.Respons
 
 	// Handle single object
 	dumpResponse(c.log(), log.DebugLevel, resp, true)
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&v); err != nil {
-		return err
+	if c.DecodeErrorWithBody {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if err := json.NewDecoder(bytes.NewReader(body)).Decode(&v); err != nil {
+			if len(body) > maxDecodeErrorBody {
+				body = body[:maxDecodeErrorBody]
+			}
+			return &DecodeError{Err: err, Body: body}
+		}
+	} else {
+		dec := json.NewDecoder(resp.Body)
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
 	}
 
 	spewDump(c.log(), log.TraceLevel, v)
@@ -153,15 +243,29 @@ func (c *RPCClient) transport() http.RoundTripper {
 }
 
 // Do retrieves values from the API and marshals them into the provided interface.
-func (c *RPCClient) Do(req *http.Request, v interface{}) (err error) {
+func (c *RPCClient) Do(req *http.Request, v interface{}) error {
+	_, err := c.DoWithResponse(req, v)
+	return err
+}
+
+// DoWithResponse is like Do but also returns the HTTP response so the caller can inspect
+// the status and headers. The response body is already consumed and closed.
+func (c *RPCClient) DoWithResponse(req *http.Request, v interface{}) (resp *http.Response, err error) {
+	_, stream := v.(streamDecoder)
+	if c.RequestTimeout != 0 && !stream && (v == nil || reflect.TypeOf(v).Kind() != reflect.Chan) {
+		ctx, cancel := context.WithTimeout(req.Context(), c.RequestTimeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
 	dumpRequest(c.log(), log.DebugLevel, req)
 
 	client := &http.Client{
 		Transport: c.transport(),
 	}
-	resp, err := client.Do(req)
+	resp, err = client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() {
@@ -170,15 +274,15 @@ func (c *RPCClient) Do(req *http.Request, v interface{}) (err error) {
 		}
 	}()
 	if resp.StatusCode == http.StatusNoContent {
-		return nil
+		return resp, nil
 	}
 
 	statusClass := resp.StatusCode / 100
 	if statusClass == 2 {
 		if v == nil {
-			return nil
+			return resp, nil
 		}
-		return c.handleNormalResponse(req.Context(), resp, v)
+		return resp, c.handleNormalResponse(req.Context(), resp, v)
 	}
 
 	// Handle errors
@@ -186,7 +290,7 @@ func (c *RPCClient) Do(req *http.Request, v interface{}) (err error) {
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return resp, err
 	}
 
 	httpErr := httpError{
@@ -194,21 +298,21 @@ func (c *RPCClient) Do(req *http.Request, v interface{}) (err error) {
 		body:     body,
 	}
 
-	if statusClass != 5 || !strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+	if (statusClass != 4 && statusClass != 5) || !strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
 		// Other errors with unknown body format (usually human readable string)
-		return &httpErr
+		return resp, &httpErr
 	}
 
 	var errs Errors
 	if err := json.Unmarshal(body, &errs); err != nil {
-		return &plainError{&httpErr, fmt.Sprintf("tezos: error decoding RPC error: %v", err)}
+		return resp, &plainError{&httpErr, fmt.Sprintf("tezos: error decoding RPC error: %v", err)}
 	}
 
 	if len(errs) == 0 {
-		return &plainError{&httpErr, "tezos: empty error response"}
+		return resp, &plainError{&httpErr, "tezos: empty error response"}
 	}
 
-	return &rpcError{
+	return resp, &rpcError{
 		httpError: &httpErr,
 		errors:    errs,
 	}
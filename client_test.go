@@ -0,0 +1,177 @@
+package tezos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoWithResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Tezos-Chain-Id", "NetXdQprcVkpaWU")
+		w.Write([]byte(`"NetXdQprcVkpaWU"`))
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+
+	req, err := c.NewRequest(context.Background(), http.MethodGet, "/chains/main/chain_id", nil)
+	require.NoError(t, err)
+
+	var chainID string
+	resp, err := c.DoWithResponse(req, &chainID)
+	require.NoError(t, err)
+	require.Equal(t, "NetXdQprcVkpaWU", chainID)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "NetXdQprcVkpaWU", resp.Header.Get("X-Tezos-Chain-Id"))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	c.RequestTimeout = 50 * time.Millisecond
+
+	s := &Service{Client: c}
+	_, err = s.GetNetworkStats(context.Background())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+}
+
+type lockedBuffer struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *lockedBuffer) Len() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.buf.Len()
+}
+
+func TestSilentClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"total_sent":"291690080","total_recv":"532639553","current_inflow":23596,"current_outflow":14972}`))
+	}))
+	defer srv.Close()
+
+	std := log.StandardLogger()
+	out, level := std.Out, std.Level
+	defer func() {
+		std.SetOutput(out)
+		std.SetLevel(level)
+	}()
+
+	var buf lockedBuffer
+	std.SetOutput(&buf)
+	std.SetLevel(log.TraceLevel)
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	c.Silent = true
+	s := &Service{Client: c}
+
+	_, err = s.GetNetworkStats(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, buf.Len())
+
+	// Log writers are asynchronous
+	c.Silent = false
+	_, err = s.GetNetworkStats(context.Background())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return buf.Len() != 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestDecodeErrorWithBody(t *testing.T) {
+	body := `{"total_sent": "not a number", "padding": "` + strings.Repeat("x", 1000) + `"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	_, err = s.GetNetworkStats(context.Background())
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "padding")
+
+	c.DecodeErrorWithBody = true
+	_, err = s.GetNetworkStats(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `\"padding\": \"xxx`)
+
+	var decodeErr *DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Len(t, decodeErr.Body, maxDecodeErrorBody)
+	require.Equal(t, body[:maxDecodeErrorBody], string(decodeErr.Body))
+	require.NotNil(t, errors.Unwrap(err))
+}
+
+func TestArrayStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[1,2,3]\n[4]\n"))
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+
+	// A plain channel receives each top level value
+	req, err := c.NewRequest(context.Background(), http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	chunks := make(chan []int, 10)
+	require.NoError(t, c.Do(req, chunks))
+	close(chunks)
+	var values [][]int
+	for v := range chunks {
+		values = append(values, v)
+	}
+	require.Equal(t, [][]int{{1, 2, 3}, {4}}, values)
+
+	// ArrayStream receives elements of the first array
+	req, err = c.NewRequest(context.Background(), http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	elems := make(chan int, 10)
+	require.NoError(t, c.Do(req, &ArrayStream{Chan: elems}))
+	close(elems)
+	var ints []int
+	for v := range elems {
+		ints = append(ints, v)
+	}
+	require.Equal(t, []int{1, 2, 3}, ints)
+
+	req, err = c.NewRequest(context.Background(), http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	require.Error(t, c.Do(req, &ArrayStream{Chan: []int{}}))
+}
@@ -0,0 +1,133 @@
+package tezos
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	adaptiveMaxRetries  = 10
+	adaptiveRetryPeriod = 50 * time.Millisecond
+)
+
+// AdaptiveConcurrency limits the number of simultaneous requests made by bulk methods.
+// The limit is increased by one after a series of successful requests and halved
+// each time the node replies with 429 Too Many Requests or 503 Service Unavailable.
+// The rejected request is retried later. Safe for concurrent use.
+type AdaptiveConcurrency struct {
+	max       int
+	mtx       sync.Mutex
+	limit     int
+	active    int
+	successes int
+	wake      chan struct{}
+}
+
+// NewAdaptiveConcurrency returns a new controller with the limit bounded by max
+func NewAdaptiveConcurrency(max int) *AdaptiveConcurrency {
+	if max < 1 {
+		max = 1
+	}
+	return &AdaptiveConcurrency{
+		max:   max,
+		limit: max,
+		wake:  make(chan struct{}),
+	}
+}
+
+// Limit returns the current concurrency limit
+func (a *AdaptiveConcurrency) Limit() int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.limit
+}
+
+func (a *AdaptiveConcurrency) acquire(ctx context.Context) error {
+	for {
+		a.mtx.Lock()
+		if a.active < a.limit {
+			a.active++
+			a.mtx.Unlock()
+			return nil
+		}
+		wake := a.wake
+		a.mtx.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func isOverloaded(err error) bool {
+	return isHTTPStatus(err, http.StatusTooManyRequests) || isHTTPStatus(err, http.StatusServiceUnavailable)
+}
+
+// release returns true if the request must be retried
+func (a *AdaptiveConcurrency) release(err error) bool {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.active--
+	overloaded := isOverloaded(err)
+	if overloaded {
+		a.limit /= 2
+		if a.limit < 1 {
+			a.limit = 1
+		}
+		a.successes = 0
+	} else if err == nil {
+		a.successes++
+		if a.successes >= a.limit {
+			if a.limit < a.max {
+				a.limit++
+			}
+			a.successes = 0
+		}
+	}
+
+	close(a.wake)
+	a.wake = make(chan struct{})
+
+	return overloaded
+}
+
+func (a *AdaptiveConcurrency) do(ctx context.Context, fn func() error) error {
+	for i := 0; ; i++ {
+		if err := a.acquire(ctx); err != nil {
+			return err
+		}
+		err := fn()
+		if !a.release(err) || i == adaptiveMaxRetries {
+			return err
+		}
+
+		t := time.NewTimer(adaptiveRetryPeriod * time.Duration(i+1))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// forEach is like forEachConcurrently but uses the service's adaptive controller if one is set.
+// The number of workers is bounded by both concurrency and the controller's maximum.
+func (s *Service) forEach(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	a := s.Concurrency
+	if a == nil {
+		return forEachConcurrently(ctx, n, concurrency, fn)
+	}
+
+	if concurrency > a.max {
+		concurrency = a.max
+	}
+	return forEachConcurrently(ctx, n, concurrency, func(ctx context.Context, i int) error {
+		return a.do(ctx, func() error { return fn(ctx, i) })
+	})
+}
@@ -0,0 +1,67 @@
+package tezos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	const capacity = 3
+
+	var inFlight, rejected int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer atomic.AddInt32(&inFlight, -1)
+		if atomic.AddInt32(&inFlight, 1) > capacity {
+			atomic.AddInt32(&rejected, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/helpers/current_level":
+			fmt.Fprint(w, `{"level":100,"level_position":99,"cycle":3,"cycle_position":3}`)
+			return
+		case "/chains/main/blocks/head/helpers/levels_in_current_cycle":
+			fmt.Fprint(w, `{"first":1,"last":32}`)
+			return
+		}
+
+		var level int
+		_, err := fmt.Sscanf(r.URL.Path, "/chains/main/blocks/%d/header", &level)
+		require.NoError(t, err)
+
+		fmt.Fprintf(w, `{"protocol":"PsBabyM1eUXZseaJdmXFApDSBqj8YBfwELoxZHHW77EMcAbbwAS","hash":"BLock%d","level":%d}`, level, level)
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	ctl := NewAdaptiveConcurrency(16)
+	s := &Service{Client: c, Concurrency: ctl}
+
+	headers, err := s.GetCycleHeaders(context.Background(), "main", 0, 16)
+	require.NoError(t, err)
+	require.Len(t, headers, 32)
+	for i, h := range headers {
+		require.Equal(t, i+1, h.Level)
+	}
+
+	require.NotZero(t, atomic.LoadInt32(&rejected))
+	require.Less(t, ctl.Limit(), 16)
+
+	// The caller's concurrency is honoured
+	atomic.StoreInt32(&rejected, 0)
+	headers, err = s.GetCycleHeaders(context.Background(), "main", 0, capacity)
+	require.NoError(t, err)
+	require.Len(t, headers, 32)
+	require.Zero(t, atomic.LoadInt32(&rejected))
+}
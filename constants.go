@@ -0,0 +1,143 @@
+package tezos
+
+import (
+	"math/big"
+)
+
+const (
+	// DefaultMinimalFees is the default minimal fee in mutez accepted by the node's mempool
+	DefaultMinimalFees = 100
+	// DefaultMinimalNanotezPerGasUnit is the default minimal fee per gas unit in nanotez accepted by the node's mempool
+	DefaultMinimalNanotezPerGasUnit = 100
+	// DefaultMinimalNanotezPerByte is the default minimal fee per byte of the signed operation in nanotez accepted by the node's mempool
+	DefaultMinimalNanotezPerByte = 1000
+)
+
+// Constants holds the protocol constants
+type Constants struct {
+	ProofOfWorkNonceSize         int       `json:"proof_of_work_nonce_size" yaml:"proof_of_work_nonce_size"`
+	NonceLength                  int       `json:"nonce_length" yaml:"nonce_length"`
+	MaxRevelationsPerBlock       int       `json:"max_revelations_per_block" yaml:"max_revelations_per_block"`
+	MaxOperationDataLength       int       `json:"max_operation_data_length" yaml:"max_operation_data_length"`
+	MaxProposalsPerDelegate      int       `json:"max_proposals_per_delegate" yaml:"max_proposals_per_delegate"`
+	PreservedCycles              int       `json:"preserved_cycles" yaml:"preserved_cycles"`
+	BlocksPerCycle               int32     `json:"blocks_per_cycle" yaml:"blocks_per_cycle"`
+	BlocksPerCommitment          int32     `json:"blocks_per_commitment" yaml:"blocks_per_commitment"`
+	BlocksPerRollSnapshot        int32     `json:"blocks_per_roll_snapshot" yaml:"blocks_per_roll_snapshot"`
+	BlocksPerVotingPeriod        int32     `json:"blocks_per_voting_period" yaml:"blocks_per_voting_period"`
+	TimeBetweenBlocks            []*BigInt `json:"time_between_blocks" yaml:"time_between_blocks"`
+	EndorsersPerBlock            int       `json:"endorsers_per_block" yaml:"endorsers_per_block"`
+	HardGasLimitPerOperation     int64     `json:"hard_gas_limit_per_operation,string" yaml:"hard_gas_limit_per_operation"`
+	HardGasLimitPerBlock         int64     `json:"hard_gas_limit_per_block,string" yaml:"hard_gas_limit_per_block"`
+	ProofOfWorkThreshold         int64     `json:"proof_of_work_threshold,string" yaml:"proof_of_work_threshold"`
+	TokensPerRoll                int64     `json:"tokens_per_roll,string" yaml:"tokens_per_roll"`
+	MichelsonMaximumTypeSize     int       `json:"michelson_maximum_type_size" yaml:"michelson_maximum_type_size"`
+	SeedNonceRevelationTip       int64     `json:"seed_nonce_revelation_tip,string" yaml:"seed_nonce_revelation_tip"`
+	OriginationSize              int       `json:"origination_size" yaml:"origination_size"`
+	BlockSecurityDeposit         int64     `json:"block_security_deposit,string" yaml:"block_security_deposit"`
+	EndorsementSecurityDeposit   int64     `json:"endorsement_security_deposit,string" yaml:"endorsement_security_deposit"`
+	BlockReward                  int64     `json:"block_reward,string" yaml:"block_reward"`
+	EndorsementReward            int64     `json:"endorsement_reward,string" yaml:"endorsement_reward"`
+	CostPerByte                  int64     `json:"cost_per_byte,string" yaml:"cost_per_byte"`
+	HardStorageLimitPerOperation int64     `json:"hard_storage_limit_per_operation,string" yaml:"hard_storage_limit_per_operation"`
+	TestChainDuration            int64     `json:"test_chain_duration,string" yaml:"test_chain_duration"`
+	QuorumMin                    int       `json:"quorum_min" yaml:"quorum_min"`
+	QuorumMax                    int       `json:"quorum_max" yaml:"quorum_max"`
+	MinProposalQuorum            int       `json:"min_proposal_quorum" yaml:"min_proposal_quorum"`
+	InitialEndorsers             int       `json:"initial_endorsers" yaml:"initial_endorsers"`
+	DelayPerMissingEndorsement   int64     `json:"delay_per_missing_endorsement,string" yaml:"delay_per_missing_endorsement"`
+}
+
+// CycleRange returns the first and the last levels of the cycle. eraCycle and eraLevel are the first cycle
+// and its first level since BlocksPerCycle has its current value (see cycle_eras of the protocol),
+// i.e. 0 and 1 if it has never changed. Use Service.GetCycleLevels to let the node resolve the range.
+func (c *Constants) CycleRange(cycle, eraCycle, eraLevel int32) (first, last int32) {
+	first = eraLevel + (cycle-eraCycle)*c.BlocksPerCycle
+	return first, first + c.BlocksPerCycle - 1
+}
+
+func valueOrDefault(v, def int64) int64 {
+	if v != 0 {
+		return v
+	}
+	return def
+}
+
+// MempoolFees holds the minimal fee settings of the node's mempool. Zero means the default value.
+type MempoolFees struct {
+	MinimalFees              int64
+	MinimalNanotezPerGasUnit int64
+	MinimalNanotezPerByte    int64
+}
+
+// MinimalFee returns the minimal fee in mutez accepted by the node's mempool
+// for an operation of opSize bytes (forged operation including the signature) and the given gas limit.
+// Nil mempool means the default settings.
+func MinimalFee(opSize int, gasLimit *big.Int, mempool *MempoolFees) *big.Int {
+	var c MempoolFees
+	if mempool != nil {
+		c = *mempool
+	}
+
+	// Everything is in nanotez
+	fee := big.NewInt(valueOrDefault(c.MinimalFees, DefaultMinimalFees) * 1000)
+	if gasLimit != nil {
+		fee.Add(fee, new(big.Int).Mul(gasLimit, big.NewInt(valueOrDefault(c.MinimalNanotezPerGasUnit, DefaultMinimalNanotezPerGasUnit))))
+	}
+	fee.Add(fee, big.NewInt(int64(opSize)*valueOrDefault(c.MinimalNanotezPerByte, DefaultMinimalNanotezPerByte)))
+
+	// Round up to mutez
+	fee.Add(fee, big.NewInt(999))
+	return fee.Quo(fee, big.NewInt(1000))
+}
+
+const (
+	// DefaultGasSafetyMargin is the default amount of gas added to the simulated gas consumption
+	DefaultGasSafetyMargin = 100
+	// DefaultStorageSafetyMargin is the default amount of bytes added to the simulated storage usage
+	DefaultStorageSafetyMargin = 20
+)
+
+// FeeConfig holds safety margins used by the fee and limits estimation
+type FeeConfig struct {
+	// GasSafetyMargin is added to the simulated gas consumption
+	GasSafetyMargin int
+	// StorageSafetyMargin is added to the simulated storage size difference
+	StorageSafetyMargin int
+	// FeeBufferMutez is added to the calculated minimal fee
+	FeeBufferMutez int64
+	// Mempool holds the minimal fee settings of the node's mempool, nil means the default settings
+	Mempool *MempoolFees
+}
+
+// DefaultFeeConfig returns the default fee configuration
+func DefaultFeeConfig() *FeeConfig {
+	return &FeeConfig{
+		GasSafetyMargin:     DefaultGasSafetyMargin,
+		StorageSafetyMargin: DefaultStorageSafetyMargin,
+	}
+}
+
+// GasLimit returns the gas limit for the given simulated gas consumption
+func (c *FeeConfig) GasLimit(consumedGas *big.Int) *big.Int {
+	limit := big.NewInt(int64(c.GasSafetyMargin))
+	if consumedGas != nil {
+		limit.Add(limit, consumedGas)
+	}
+	return limit
+}
+
+// StorageLimit returns the storage limit for the given simulated storage size difference.
+// No margin is added if the operation doesn't consume any storage.
+func (c *FeeConfig) StorageLimit(storageSize *big.Int) *big.Int {
+	if storageSize == nil || storageSize.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Add(storageSize, big.NewInt(int64(c.StorageSafetyMargin)))
+}
+
+// Fee returns the fee for an operation of opSize bytes and the given gas limit
+func (c *FeeConfig) Fee(opSize int, gasLimit *big.Int) *big.Int {
+	fee := MinimalFee(opSize, gasLimit, c.Mempool)
+	return fee.Add(fee, big.NewInt(c.FeeBufferMutez))
+}
@@ -0,0 +1,140 @@
+package tezos
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimalFee(t *testing.T) {
+	tests := []struct {
+		opSize   int
+		gasLimit int64
+		mempool  *MempoolFees
+		expected int64
+	}{
+		// Simple transfer to an implicit account
+		{opSize: 154, gasLimit: 10207, expected: 1275},
+		{opSize: 154, gasLimit: 10300, expected: 1284},
+		// Reveal
+		{opSize: 156, gasLimit: 10000, expected: 1256},
+		{opSize: 154, gasLimit: 10207, mempool: &MempoolFees{MinimalFees: 200, MinimalNanotezPerGasUnit: 200, MinimalNanotezPerByte: 2000}, expected: 2550},
+	}
+
+	for _, test := range tests {
+		fee := MinimalFee(test.opSize, big.NewInt(test.gasLimit), test.mempool)
+		require.Equal(t, big.NewInt(test.expected), fee)
+	}
+}
+
+func TestFeeConfig(t *testing.T) {
+	tests := []struct {
+		config               *FeeConfig
+		consumedGas          int64
+		storageSize          int64
+		expectedGasLimit     int64
+		expectedStorageLimit int64
+		expectedFee          int64
+	}{
+		{
+			config:               DefaultFeeConfig(),
+			consumedGas:          10207,
+			storageSize:          0,
+			expectedGasLimit:     10307,
+			expectedStorageLimit: 0,
+			expectedFee:          1285,
+		},
+		{
+			config:               DefaultFeeConfig(),
+			consumedGas:          25000,
+			storageSize:          67,
+			expectedGasLimit:     25100,
+			expectedStorageLimit: 87,
+			expectedFee:          2764,
+		},
+		{
+			config:               &FeeConfig{GasSafetyMargin: 1000, StorageSafetyMargin: 100, FeeBufferMutez: 500},
+			consumedGas:          25000,
+			storageSize:          67,
+			expectedGasLimit:     26000,
+			expectedStorageLimit: 167,
+			expectedFee:          3354,
+		},
+		{
+			config:               &FeeConfig{GasSafetyMargin: 100, Mempool: &MempoolFees{MinimalFees: 200, MinimalNanotezPerGasUnit: 200, MinimalNanotezPerByte: 2000}},
+			consumedGas:          10207,
+			storageSize:          0,
+			expectedGasLimit:     10307,
+			expectedStorageLimit: 0,
+			expectedFee:          2570,
+		},
+	}
+
+	for _, test := range tests {
+		gasLimit := test.config.GasLimit(big.NewInt(test.consumedGas))
+		require.Equal(t, big.NewInt(test.expectedGasLimit), gasLimit)
+		require.Equal(t, big.NewInt(test.expectedStorageLimit), test.config.StorageLimit(big.NewInt(test.storageSize)))
+		require.Equal(t, big.NewInt(test.expectedFee), test.config.Fee(154, gasLimit))
+	}
+}
+
+func TestCycleRange(t *testing.T) {
+	florence := Constants{BlocksPerCycle: 4096}
+	first, last := florence.CycleRange(387, 0, 1)
+	require.Equal(t, []int32{1585153, 1589248}, []int32{first, last})
+
+	// Granada has doubled blocks_per_cycle starting from cycle 388
+	granada := Constants{BlocksPerCycle: 8192}
+	first, last = granada.CycleRange(388, 388, 1589249)
+	require.Equal(t, []int32{1589249, 1597440}, []int32{first, last})
+	first, last = granada.CycleRange(400, 388, 1589249)
+	require.Equal(t, []int32{1687553, 1695744}, []int32{first, last})
+}
+
+func TestCachedConstants(t *testing.T) {
+	var (
+		protocol      atomic.Value
+		constantsReqs int32
+	)
+	protocol.Store("PsBabyM1eUXZseaJdmXFApDSBqj8YBfwELoxZHHW77EMcAbbwAS")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/header":
+			fmt.Fprintf(w, `{"protocol":%q,"chain_id":"NetXdQprcVkpaWU","hash":"BLockHead","level":1}`, protocol.Load().(string))
+		case "/chains/main/blocks/BLockHead/context/constants":
+			n := atomic.AddInt32(&constantsReqs, 1)
+			fmt.Fprintf(w, `{"blocks_per_cycle":%d}`, 4096*n)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		constants, err := s.CachedConstants(ctx, "main")
+		require.NoError(t, err)
+		require.Equal(t, int32(4096), constants.BlocksPerCycle)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&constantsReqs))
+
+	protocol.Store("PsCARTHAGazKbHtnKfLzQg3kms52kSRpgnDY982a9oYsSXRLQEb")
+
+	constants, err := s.CachedConstants(ctx, "main")
+	require.NoError(t, err)
+	require.Equal(t, int32(8192), constants.BlocksPerCycle)
+	require.Equal(t, int32(2), atomic.LoadInt32(&constantsReqs))
+}
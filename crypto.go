@@ -0,0 +1,51 @@
+package tezos
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ScriptExprHash returns the script expression hash (expr...) of packed data used as a big map key
+func ScriptExprHash(packed HexBytes) string {
+	sum := blake2b.Sum256(packed)
+	return base58CheckEncode(prefixScriptExpr, sum[:])
+}
+
+// PublicKeyHash returns the public key hash (tz1, tz2 or tz3 address) of a Base58Check encoded public key
+func PublicKeyHash(pk string) (string, error) {
+	var prefix, hashPrefix []byte
+	switch {
+	case strings.HasPrefix(pk, "edpk"):
+		prefix, hashPrefix = prefixEd25519PK, prefixEd25519PKHash
+	case strings.HasPrefix(pk, "sppk"):
+		prefix, hashPrefix = prefixSecp256k1PK, prefixSecp256k1PKHash
+	case strings.HasPrefix(pk, "p2pk"):
+		prefix, hashPrefix = prefixP256PK, prefixP256PKHash
+	default:
+		return "", fmt.Errorf("tezos: unknown public key type: %q", pk)
+	}
+
+	key, err := base58CheckDecode(pk, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	h, err := blake2b.New(20, nil)
+	if err != nil {
+		return "", err
+	}
+	h.Write(key)
+
+	return base58CheckEncode(hashPrefix, h.Sum(nil)), nil
+}
+
+// PublicKeyMatchesHash returns true if the Base58Check encoded public key hashes to pkh
+func PublicKeyMatchesHash(pubKey, pkh string) (bool, error) {
+	h, err := PublicKeyHash(pubKey)
+	if err != nil {
+		return false, err
+	}
+	return h == pkh, nil
+}
@@ -0,0 +1,74 @@
+package tezos
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackDataScriptExprHash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/chains/main/blocks/head/helpers/scripts/pack_data", r.URL.Path)
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"data":{"string":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"},"type":{"prim":"address"}}`, string(body))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"packed":"050a000000160000` + "02298c03ed7d454a101eb7022bc95f7e5f41ac78" + `","gas":"799979"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	var data, typ map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"string":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}`), &data))
+	require.NoError(t, json.Unmarshal([]byte(`{"prim":"address"}`), &typ))
+
+	packed, err := s.PackData(context.Background(), "main", "head", data, typ)
+	require.NoError(t, err)
+	require.Len(t, packed, 28)
+	require.Equal(t, "expruH3qgknRBJVLVkwdzf6wfBxd7Y1uqNxr7zuMFxTC12e5PacLfv", ScriptExprHash(packed))
+}
+
+func TestPublicKeyHash(t *testing.T) {
+	pkh, err := PublicKeyHash("edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yav")
+	require.NoError(t, err)
+	require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", pkh)
+
+	_, err = PublicKeyHash("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.Error(t, err)
+}
+
+func TestPublicKeyMatchesHash(t *testing.T) {
+	pairs := []struct {
+		pk  string
+		pkh string
+	}{
+		{"edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yav", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"},
+		{"sppk7aEFdrScsCDxdaQ7Ev1JxpWZESrEK6UsWRhr79JfGKkPYGTsudN", "tz2BCeQSi5ETyKJsob61pWCoQvoGtsrJBEt2"},
+		{"p2pk67L57Q7vcgLkMrKXctFRKs5JSLR6qjiw1riJaFyakWpTv9QSkRf", "tz3bqAfFRnSA6dfPRG8XR6MBMmo6HZTTG44V"},
+	}
+
+	for i, p := range pairs {
+		ok, err := PublicKeyMatchesHash(p.pk, p.pkh)
+		require.NoError(t, err)
+		require.True(t, ok, p.pk)
+
+		// Mismatch
+		ok, err = PublicKeyMatchesHash(p.pk, pairs[(i+1)%len(pairs)].pkh)
+		require.NoError(t, err)
+		require.False(t, ok, p.pk)
+	}
+
+	_, err := PublicKeyMatchesHash("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.Error(t, err)
+}
@@ -2,8 +2,10 @@ package tezos
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -15,6 +17,10 @@ const (
 	ErrorKindBranch = "branch"
 )
 
+// ErrPruned is matched by errors.Is if the requested data was pruned by the node (HTTP 410 Gone or a context storage error).
+// Use an archive node to get it.
+var ErrPruned = errors.New("tezos: data is pruned")
+
 // Error is a Tezos error as documented on http://tezos.gitlab.io/mainnet/api/errors.html.
 type Error interface {
 	error
@@ -26,6 +32,42 @@ type Error interface {
 type GenericError struct {
 	ID   string `json:"id"`
 	Kind string `json:"kind"`
+	// Details holds protocol specific fields, e.g. contract, balance and amount of balance_too_low
+	Details map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (e *GenericError) UnmarshalJSON(data []byte) error {
+	type suppressJSONUnmarshaller GenericError
+	if err := json.Unmarshal(data, (*suppressJSONUnmarshaller)(e)); err != nil {
+		return err
+	}
+
+	var details map[string]interface{}
+	if err := json.Unmarshal(data, &details); err != nil {
+		return err
+	}
+	delete(details, "id")
+	delete(details, "kind")
+
+	if len(details) != 0 {
+		e.Details = details
+	} else {
+		e.Details = nil
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (e *GenericError) MarshalJSON() ([]byte, error) {
+	v := make(map[string]interface{}, len(e.Details)+2)
+	for k, val := range e.Details {
+		v[k] = val
+	}
+	v["id"] = e.ID
+	v["kind"] = e.Kind
+	return json.Marshal(v)
 }
 
 func (e *GenericError) Error() string {
@@ -90,6 +132,39 @@ func (e Errors) Error() string {
 	return e[0].Error()
 }
 
+// HasID returns true if any of the errors has the given id. The protocol prefix may be omitted
+// as well as any leading or trailing dot separated components, i.e. "gas_exhausted" matches
+// "proto.005-PsBabyM1.gas_exhausted.operation".
+func (e Errors) HasID(id string) bool {
+	for _, err := range e {
+		if matchErrorID(err.ErrorID(), id) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCounterError returns true if the operation was rejected because of a stale or premature counter.
+// The counter must be re-fetched and the operation rebuilt and signed again, see Service.InjectWithCounterRetry.
+func (e Errors) IsCounterError() bool {
+	return e.HasID("counter_in_the_future") || e.HasID("counter_in_the_past")
+}
+
+// rpcErrors returns Tezos errors carried by err if any
+func rpcErrors(err error) Errors {
+	switch e := err.(type) {
+	case RPCError:
+		return e.Errors()
+	case Errors:
+		return e
+	}
+	return nil
+}
+
+func matchErrorID(full, id string) bool {
+	return id != "" && strings.Contains("."+full+".", "."+id+".")
+}
+
 // ErrorID returns Tezos error id
 func (e Errors) ErrorID() string {
 	if len(e) == 0 {
@@ -131,6 +206,10 @@ func (e *httpError) Response() *http.Response {
 	return e.response
 }
 
+func (e *httpError) Is(target error) bool {
+	return target == ErrPruned && e.response.StatusCode == http.StatusGone
+}
+
 type rpcError struct {
 	*httpError
 	errors Errors
@@ -152,6 +231,36 @@ func (e *rpcError) Errors() []Error {
 	return e.errors
 }
 
+func (e *rpcError) Is(target error) bool {
+	if target != ErrPruned {
+		return false
+	}
+	for _, err := range e.errors {
+		if strings.HasSuffix(err.ErrorID(), "context.storage_error") {
+			return true
+		}
+	}
+	return e.httpError.Is(target)
+}
+
+// maxDecodeErrorBody is the maximum number of response body bytes retained by DecodeError
+const maxDecodeErrorBody = 512
+
+// DecodeError is returned if the response can't be decoded and RPCClient.DecodeErrorWithBody is set
+type DecodeError struct {
+	Err  error
+	Body []byte // truncated response body
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%v (response body: %q)", e.Err, e.Body)
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
 type plainError struct {
 	*httpError
 	msg string
@@ -161,6 +270,13 @@ func (e *plainError) Error() string {
 	return e.msg
 }
 
+func isHTTPStatus(err error, code int) bool {
+	if e, ok := err.(HTTPStatus); ok {
+		return e.StatusCode() == code
+	}
+	return false
+}
+
 var (
 	_ Error    = &GenericError{}
 	_ Error    = Errors{}
@@ -0,0 +1,117 @@
+package tezos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrPruned(t *testing.T) {
+	tests := []struct {
+		status      int
+		fixture     string
+		contentType string
+		pruned      bool
+	}{
+		{status: 500, fixture: "fixtures/error.json", contentType: "application/json", pruned: true},
+		{status: 410, fixture: "fixtures/empty.json", pruned: true},
+		{status: 404, fixture: "fixtures/empty.json", pruned: false},
+		{status: 400, fixture: "fixtures/block/inconsistent_chain_error.json", contentType: "application/json", pruned: false},
+	}
+
+	for _, test := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf, err := ioutil.ReadFile(test.fixture)
+			require.NoError(t, err)
+			if test.contentType != "" {
+				w.Header().Set("Content-Type", test.contentType)
+			}
+			w.WriteHeader(test.status)
+			w.Write(buf)
+		}))
+
+		c, err := NewRPCClient(srv.URL)
+		require.NoError(t, err)
+		s := &Service{Client: c}
+
+		_, err = s.GetContractBalance(context.Background(), "main", "1", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+		require.Error(t, err)
+		require.Equal(t, test.pruned, errors.Is(err, ErrPruned), test.status)
+
+		srv.Close()
+	}
+}
+
+func TestErrorsDetails(t *testing.T) {
+	tests := []struct {
+		fixture string
+		id      string
+		details map[string]interface{}
+		has     []string
+		hasNot  []string
+	}{
+		{
+			fixture: "fixtures/errors/balance_too_low.json",
+			id:      "proto.005-PsBabyM1.contract.balance_too_low",
+			details: map[string]interface{}{"contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "balance": "2000", "amount": "1000000"},
+			has:     []string{"balance_too_low", "contract.balance_too_low", "proto.005-PsBabyM1.contract.balance_too_low"},
+			hasNot:  []string{"gas_exhausted", "balance_too", ""},
+		},
+		{
+			fixture: "fixtures/errors/counter_in_the_future.json",
+			id:      "proto.005-PsBabyM1.contract.counter_in_the_future",
+			details: map[string]interface{}{"contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "expected": "11", "found": "15"},
+			has:     []string{"counter_in_the_future"},
+			hasNot:  []string{"counter_in_the_past"},
+		},
+		{
+			fixture: "fixtures/errors/gas_exhausted.json",
+			id:      "proto.005-PsBabyM1.gas_exhausted.operation",
+			has:     []string{"gas_exhausted", "gas_exhausted.operation", "runtime_error"},
+			hasNot:  []string{"storage_exhausted"},
+		},
+	}
+
+	for _, test := range tests {
+		data, err := ioutil.ReadFile(test.fixture)
+		require.NoError(t, err)
+
+		var errs Errors
+		require.NoError(t, json.Unmarshal(data, &errs))
+		require.Equal(t, test.id, errs.ErrorID())
+		require.Equal(t, "temporary", errs.ErrorKind())
+		require.Equal(t, test.details, errs[0].(*GenericError).Details)
+
+		for _, id := range test.has {
+			require.True(t, errs.HasID(id), id)
+		}
+		for _, id := range test.hasNot {
+			require.False(t, errs.HasID(id), id)
+		}
+
+		// Details survive the round trip
+		out, err := json.Marshal(errs)
+		require.NoError(t, err)
+		require.JSONEq(t, string(data), string(out))
+	}
+}
+
+func TestIsCounterError(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/errors/counter_in_the_future.json")
+	require.NoError(t, err)
+
+	var errs Errors
+	require.NoError(t, json.Unmarshal(data, &errs))
+	require.True(t, errs.IsCounterError())
+
+	data, err = ioutil.ReadFile("fixtures/errors/balance_too_low.json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &errs))
+	require.False(t, errs.IsCounterError())
+}
@@ -0,0 +1,63 @@
+package tezos
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+var balanceUpdatesCSVHeader = []string{"level", "kind", "account", "category", "change", "origin"}
+
+func balanceUpdateCSVRecord(level int, u BalanceUpdate) []string {
+	var account, category string
+	switch u := u.(type) {
+	case *ContractBalanceUpdate:
+		account = u.Contract
+	case *FreezerBalanceUpdate:
+		account, category = u.Delegate, u.Category
+	}
+
+	return []string{strconv.Itoa(level), u.BalanceUpdateKind(), account, category, strconv.FormatInt(u.BalanceUpdateChange(), 10), u.BalanceUpdateOrigin()}
+}
+
+// blockBalanceUpdates returns block level balance updates followed by ones of all operations
+func blockBalanceUpdates(block *Block) BalanceUpdates {
+	res := append(BalanceUpdates(nil), block.Metadata.BalanceUpdates...)
+	for _, pass := range block.Operations {
+		for _, op := range pass {
+			res = append(res, op.balanceUpdates()...)
+		}
+	}
+	return res
+}
+
+// ExportBalanceUpdatesCSV writes balance updates of blocks in the range [from, to] to w as CSV
+// with columns level, kind, account (contract or delegate), category, change and origin.
+// Output is flushed after each block.
+func (s *Service) ExportBalanceUpdatesCSV(ctx context.Context, chainID string, from, to int32, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(balanceUpdatesCSVHeader); err != nil {
+		return err
+	}
+
+	for level := from; level <= to; level++ {
+		block, err := s.GetBlock(ctx, chainID, BlockLevel(level))
+		if err != nil {
+			return err
+		}
+
+		for _, u := range blockBalanceUpdates(block) {
+			if err := cw.Write(balanceUpdateCSVRecord(block.Header.Level, u)); err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package tezos
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportBalanceUpdatesCSV(t *testing.T) {
+	blocks := map[string]string{
+		"/chains/main/blocks/100": `{
+			"header": {"level": 100},
+			"metadata": {
+				"balance_updates": [
+					{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-512000000", "origin": "block"},
+					{"kind": "freezer", "category": "deposits", "delegate": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "cycle": 1, "change": "512000000", "origin": "block"}
+				]
+			},
+			"operations": [[], [], [], []]
+		}`,
+		"/chains/main/blocks/101": `{
+			"header": {"level": 101},
+			"metadata": {"balance_updates": []},
+			"operations": [[], [], [], [{
+				"contents": [{
+					"kind": "transaction",
+					"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+					"fee": "1420",
+					"amount": "1000000",
+					"destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+					"metadata": {
+						"balance_updates": [
+							{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-1420"},
+							{"kind": "freezer", "category": "fees", "delegate": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "level": 0, "change": "1420"}
+						],
+						"operation_result": {
+							"status": "applied",
+							"balance_updates": [
+								{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-1000000"},
+								{"kind": "contract", "contract": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "change": "1000000"}
+							]
+						}
+					}
+				}]
+			}, {
+				"contents": [{
+					"kind": "transaction",
+					"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+					"fee": "1420",
+					"amount": "5000000000",
+					"destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+					"metadata": {
+						"balance_updates": [
+							{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-1420"},
+							{"kind": "freezer", "category": "fees", "delegate": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "level": 0, "change": "1420"}
+						],
+						"operation_result": {
+							"status": "failed",
+							"balance_updates": [
+								{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-5000000000"},
+								{"kind": "contract", "contract": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "change": "5000000000"}
+							]
+						}
+					}
+				}]
+			}]]
+		}`,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := blocks[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, b)
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	var buf bytes.Buffer
+	require.NoError(t, s.ExportBalanceUpdatesCSV(context.Background(), "main", 100, 101, &buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"level", "kind", "account", "category", "change", "origin"},
+		{"100", "contract", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "", "-512000000", "block"},
+		{"100", "freezer", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "deposits", "512000000", "block"},
+		{"101", "contract", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "", "-1420", ""},
+		{"101", "freezer", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "fees", "1420", ""},
+		{"101", "contract", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "", "-1000000", ""},
+		{"101", "contract", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "", "1000000", ""},
+		// Only the fee of the failed operation is exported
+		{"101", "contract", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "", "-1420", ""},
+		{"101", "freezer", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "fees", "1420", ""},
+	}, records)
+}
@@ -0,0 +1,282 @@
+package tezos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Operation tags (Babylon and later)
+const (
+	tagEndorsement = 0
+	tagReveal      = 107
+	tagTransaction = 108
+	tagDelegation  = 110
+)
+
+func forgeZarith(buf *bytes.Buffer, v *BigInt) error {
+	if v == nil {
+		buf.WriteByte(0)
+		return nil
+	}
+	if v.Sign() < 0 {
+		return fmt.Errorf("tezos: negative natural number: %v", v)
+	}
+
+	x := new(big.Int).Set(&v.Int)
+	mask := big.NewInt(0x7f)
+	for {
+		b := byte(new(big.Int).And(x, mask).Uint64())
+		x.Rsh(x, 7)
+		if x.Sign() == 0 {
+			buf.WriteByte(b)
+			return nil
+		}
+		buf.WriteByte(b | 0x80)
+	}
+}
+
+func forgePublicKeyHash(buf *bytes.Buffer, pkh string) error {
+	var (
+		tag    byte
+		prefix []byte
+	)
+	switch {
+	case strings.HasPrefix(pkh, "tz1"):
+		tag, prefix = 0, prefixEd25519PKHash
+	case strings.HasPrefix(pkh, "tz2"):
+		tag, prefix = 1, prefixSecp256k1PKHash
+	case strings.HasPrefix(pkh, "tz3"):
+		tag, prefix = 2, prefixP256PKHash
+	default:
+		return fmt.Errorf("tezos: unknown public key hash type: %q", pkh)
+	}
+
+	data, err := base58CheckDecode(pkh, prefix)
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(tag)
+	buf.Write(data)
+	return nil
+}
+
+func forgePublicKey(buf *bytes.Buffer, pk string) error {
+	var (
+		tag    byte
+		prefix []byte
+	)
+	switch {
+	case strings.HasPrefix(pk, "edpk"):
+		tag, prefix = 0, prefixEd25519PK
+	case strings.HasPrefix(pk, "sppk"):
+		tag, prefix = 1, prefixSecp256k1PK
+	case strings.HasPrefix(pk, "p2pk"):
+		tag, prefix = 2, prefixP256PK
+	default:
+		return fmt.Errorf("tezos: unknown public key type: %q", pk)
+	}
+
+	data, err := base58CheckDecode(pk, prefix)
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(tag)
+	buf.Write(data)
+	return nil
+}
+
+func forgeContractID(buf *bytes.Buffer, id string) error {
+	if strings.HasPrefix(id, "KT1") {
+		data, err := base58CheckDecode(id, prefixContractHash)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(1)
+		buf.Write(data)
+		buf.WriteByte(0) // padding
+		return nil
+	}
+
+	buf.WriteByte(0)
+	return forgePublicKeyHash(buf, id)
+}
+
+type managerOperationFields struct {
+	Source       string
+	Fee          *BigInt
+	Counter      *BigInt
+	GasLimit     *BigInt
+	StorageLimit *BigInt
+}
+
+func (m *managerOperationFields) forge(buf *bytes.Buffer) error {
+	if err := forgePublicKeyHash(buf, m.Source); err != nil {
+		return err
+	}
+	for _, v := range []*BigInt{m.Fee, m.Counter, m.GasLimit, m.StorageLimit} {
+		if err := forgeZarith(buf, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func forgeOperationElem(buf *bytes.Buffer, el OperationElem) error {
+	switch el := el.(type) {
+	case *EndorsementOperationElem:
+		buf.WriteByte(tagEndorsement)
+		var tmp [4]byte
+		binary.BigEndian.PutUint32(tmp[:], uint32(el.Level))
+		buf.Write(tmp[:])
+
+	case *RevealOperationElem:
+		buf.WriteByte(tagReveal)
+		m := managerOperationFields{el.Source, el.Fee, el.Counter, el.GasLimit, el.StorageLimit}
+		if err := m.forge(buf); err != nil {
+			return err
+		}
+		return forgePublicKey(buf, el.PublicKey)
+
+	case *TransactionOperationElem:
+		buf.WriteByte(tagTransaction)
+		m := managerOperationFields{el.Source, el.Fee, el.Counter, el.GasLimit, el.StorageLimit}
+		if err := m.forge(buf); err != nil {
+			return err
+		}
+		if err := forgeZarith(buf, el.Amount); err != nil {
+			return err
+		}
+		if err := forgeContractID(buf, el.Destination); err != nil {
+			return err
+		}
+		if el.Parameters != nil {
+			return fmt.Errorf("tezos: forging of transaction parameters is not supported")
+		}
+		buf.WriteByte(0)
+
+	case *DelegationOperationElem:
+		buf.WriteByte(tagDelegation)
+		m := managerOperationFields{el.Source, el.Fee, el.Counter, el.GasLimit, el.StorageLimit}
+		if err := m.forge(buf); err != nil {
+			return err
+		}
+		if el.Delegate == "" {
+			buf.WriteByte(0)
+			return nil
+		}
+		buf.WriteByte(0xff)
+		return forgePublicKeyHash(buf, el.Delegate)
+
+	default:
+		return fmt.Errorf("tezos: forging of %q operations is not supported", el.OperationElemKind())
+	}
+
+	return nil
+}
+
+// ForgeInput returns the contents in the JSON format accepted by the forge, run and preapply RPCs.
+// Metadata, unset fields and empty legacy fields are omitted
+func (e OperationElements) ForgeInput() ([]json.RawMessage, error) {
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &contents); err != nil {
+		return nil, err
+	}
+
+	res := make([]json.RawMessage, len(contents))
+	for i, el := range contents {
+		delete(el, "metadata")
+		for k, v := range el {
+			if string(v) == "null" {
+				delete(el, k)
+			}
+		}
+		// Pre-Babylon origination field
+		if string(el["managerPubkey"]) == `""` {
+			delete(el, "managerPubkey")
+		}
+
+		if res[i], err = json.Marshal(el); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Bytes returns the forged (unsigned) operation
+func (o *Operation) Bytes() (HexBytes, error) {
+	var buf bytes.Buffer
+
+	branch, err := base58CheckDecode(o.Branch, prefixBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(branch)
+
+	for _, el := range o.Contents {
+		if err := forgeOperationElem(&buf, el); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SignedBytes returns the forged operation followed by the signature suitable for injection
+func (o *Operation) SignedBytes() (HexBytes, error) {
+	data, err := o.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := decodeSignature(o.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, sig...), nil
+}
+
+// AssembleSignedOperation appends the raw bytes of the Base58Check encoded signature to the forged operation
+// and returns the hex string suitable for injection. Useful when forging and signing happen on different hosts.
+func AssembleSignedOperation(forged HexBytes, signature string) (signedHex string, err error) {
+	sig, err := decodeSignature(signature)
+	if err != nil {
+		return "", err
+	}
+	if len(sig) != 64 {
+		return "", fmt.Errorf("tezos: invalid signature length: %d", len(sig))
+	}
+
+	signed := make([]byte, 0, len(forged)+len(sig))
+	signed = append(signed, forged...)
+	signed = append(signed, sig...)
+	return hex.EncodeToString(signed), nil
+}
+
+func decodeSignature(sig string) ([]byte, error) {
+	var prefix []byte
+	switch {
+	case strings.HasPrefix(sig, "edsig"):
+		prefix = prefixEd25519Signature
+	case strings.HasPrefix(sig, "spsig1"):
+		prefix = prefixSecp256k1Signature
+	case strings.HasPrefix(sig, "p2sig"):
+		prefix = prefixP256Signature
+	case strings.HasPrefix(sig, "sig"):
+		prefix = prefixGenericSignature
+	default:
+		return nil, fmt.Errorf("tezos: unknown signature type: %q", sig)
+	}
+	return base58CheckDecode(sig, prefix)
+}
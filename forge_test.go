@@ -0,0 +1,64 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedBytes(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/signed.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+
+	signed, err := op.SignedBytes()
+	require.NoError(t, err)
+
+	expected := "56cdce87b1d052cedfd8019113916f4394f1a300ceca771c197ee18623cb3c6c" + // branch
+		"6b0002298c03ed7d454a101eb7022bc95f7e5f41ac78f5091e904e00004798d2cc98473d7e250c898885718afd2e4efbcb1a1595ab9730761ed830de0f" + // reveal
+		"6c0002298c03ed7d454a101eb7022bc95f7e5f41ac788c0b1fc3508102c0843d0000e7670f32038107a59a2b9cfefae36ea21f5aa63c00" + // transaction
+		"6e0002298c03ed7d454a101eb7022bc95f7e5f41ac78e90920904e00ff00e7670f32038107a59a2b9cfefae36ea21f5aa63c" + // delegation
+		"1f035ceb95bcd63558b3ae5852f85afd0cf96c085b41f8fc32ad798cc24c3a9eb04bf359e3bccd5159654e3a1aabc645e5476aa134f1e1dd9eb93c12abf5bb01" // signature
+	require.Equal(t, expected, hex.EncodeToString(signed))
+
+	unsigned, err := op.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, signed[:len(signed)-64], unsigned)
+}
+
+func TestSignedBytesUnsupported(t *testing.T) {
+	op := Operation{
+		Branch:    "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8",
+		Contents:  OperationElements{&GenericOperationElem{Kind: "smart_rollup_publish"}},
+		Signature: "sigS3d9wfEFuChEqLetCxf4G8QYAjWL7ND3F8amMPVPDS2RwQqkeKU9hbrEXk7GG7U2aPcWkTA3uTdNzz4gkAb8jSy8hUc51",
+	}
+	_, err := op.SignedBytes()
+	require.EqualError(t, err, `tezos: forging of "smart_rollup_publish" operations is not supported`)
+}
+
+func TestAssembleSignedOperation(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/signed.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+
+	// Forged online, signed offline
+	forged, err := op.Bytes()
+	require.NoError(t, err)
+
+	signed, err := AssembleSignedOperation(forged, op.Signature)
+	require.NoError(t, err)
+
+	expected, err := op.SignedBytes()
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(expected), signed)
+
+	_, err = AssembleSignedOperation(forged, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.Error(t, err)
+}
@@ -6,5 +6,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/sirupsen/logrus v1.4.2
 	github.com/stretchr/testify v1.4.0
+	golang.org/x/crypto v0.0.0-20190701094942-4def268fd1a4
 	gopkg.in/yaml.v3 v3.0.0-20190709130402-674ba3eaed22
 )
@@ -0,0 +1,95 @@
+package tezos
+
+import (
+	"fmt"
+)
+
+// IDKind is a kind of a Base58Check encoded Tezos id
+type IDKind int
+
+// Id kinds
+const (
+	IDBlock         IDKind = iota // B...
+	IDOperation                   // o...
+	IDProtocol                    // P...
+	IDChainID                     // Net...
+	IDPublicKeyHash               // tz1, tz2 or tz3
+	IDContract                    // tz1, tz2, tz3 or KT1
+	IDPublicKey                   // edpk, sppk or p2pk
+	IDScriptExpr                  // expr...
+)
+
+func (k IDKind) String() string {
+	switch k {
+	case IDBlock:
+		return "block hash"
+	case IDOperation:
+		return "operation hash"
+	case IDProtocol:
+		return "protocol hash"
+	case IDChainID:
+		return "chain id"
+	case IDPublicKeyHash:
+		return "public key hash"
+	case IDContract:
+		return "contract id"
+	case IDPublicKey:
+		return "public key"
+	case IDScriptExpr:
+		return "script expression hash"
+	}
+	return fmt.Sprintf("IDKind(%d)", int(k))
+}
+
+type idEncoding struct {
+	prefix []byte
+	length int
+}
+
+var (
+	pkhEncodings = []idEncoding{
+		{prefixEd25519PKHash, 20},
+		{prefixSecp256k1PKHash, 20},
+		{prefixP256PKHash, 20},
+	}
+
+	idEncodings = map[IDKind][]idEncoding{
+		IDBlock:         {{prefixBlockHash, 32}},
+		IDOperation:     {{prefixOperationHash, 32}},
+		IDProtocol:      {{prefixProtocolHash, 32}},
+		IDChainID:       {{prefixChainID, 4}},
+		IDPublicKeyHash: pkhEncodings,
+		IDContract:      append([]idEncoding{{prefixContractHash, 20}}, pkhEncodings...),
+		IDPublicKey: {
+			{prefixEd25519PK, 32},
+			{prefixSecp256k1PK, 33},
+			{prefixP256PK, 33},
+		},
+		IDScriptExpr: {{prefixScriptExpr, 32}},
+	}
+)
+
+// ValidateID checks that s is a valid Base58Check encoded id of the given kind
+func ValidateID(s string, kind IDKind) error {
+	encodings, ok := idEncodings[kind]
+	if !ok {
+		return fmt.Errorf("tezos: unknown id kind: %v", kind)
+	}
+
+	var err error
+	for _, enc := range encodings {
+		var payload []byte
+		if payload, err = base58CheckDecode(s, enc.prefix); err != nil {
+			continue
+		}
+		if len(payload) != enc.length {
+			return fmt.Errorf("tezos: invalid %v length: %q", kind, s)
+		}
+		return nil
+	}
+
+	if err == errBase58Checksum {
+		return err
+	}
+	return fmt.Errorf("tezos: %q is not a valid %v", s, kind)
+}
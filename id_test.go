@@ -0,0 +1,52 @@
+package tezos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateID(t *testing.T) {
+	valid := []struct {
+		id   string
+		kind IDKind
+	}{
+		{"BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", IDBlock},
+		{"opEatwYFvwuUM2aEa9cUU1ofMzsi46bYwiUhPLENXpLkjpps4Xq", IDOperation},
+		{"PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", IDProtocol},
+		{"NetXZUqeBjDnWde", IDChainID},
+		{"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", IDPublicKeyHash},
+		{"tz2BCeQSi5ETyKJsob61pWCoQvoGtsrJBEt2", IDPublicKeyHash},
+		{"tz3bqAfFRnSA6dfPRG8XR6MBMmo6HZTTG44V", IDPublicKeyHash},
+		{"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", IDContract},
+		{"KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", IDContract},
+		{"edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yav", IDPublicKey},
+		{"sppk7aEFdrScsCDxdaQ7Ev1JxpWZESrEK6UsWRhr79JfGKkPYGTsudN", IDPublicKey},
+		{"p2pk67L57Q7vcgLkMrKXctFRKs5JSLR6qjiw1riJaFyakWpTv9QSkRf", IDPublicKey},
+		{"expruH3qgknRBJVLVkwdzf6wfBxd7Y1uqNxr7zuMFxTC12e5PacLfv", IDScriptExpr},
+	}
+
+	for _, v := range valid {
+		require.NoError(t, ValidateID(v.id, v.kind), v.id)
+	}
+
+	invalid := []struct {
+		id   string
+		kind IDKind
+	}{
+		{"BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", IDChainID},
+		{"BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", IDOperation},
+		{"opEatwYFvwuUM2aEa9cUU1ofMzsi46bYwiUhPLENXpLkjpps4Xq", IDBlock},
+		{"NetXZUqeBjDnWde", IDBlock},
+		{"KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", IDPublicKeyHash},
+		{"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", IDPublicKey},
+		{"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSy", IDContract}, // checksum
+		{"BL0", IDBlock},
+		{"", IDBlock},
+		{"BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", IDKind(100)},
+	}
+
+	for _, v := range invalid {
+		require.Error(t, ValidateID(v.id, v.kind), v.id)
+	}
+}
@@ -0,0 +1,84 @@
+package tezos
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultOperationCacheSize is the number of operation hashes remembered by Service.FindOperation
+const defaultOperationCacheSize = 10000
+
+// defaultBlockCacheSize is the number of scanned block hashes remembered by Service.FindOperation to detect reorganizations
+const defaultBlockCacheSize = 1000
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+// lruCache is a bounded map evicting least recently used entries. Safe for concurrent use.
+type lruCache struct {
+	size  int
+	mtx   sync.Mutex
+	list  *list.List
+	items map[string]*list.Element
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		list:  list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) (interface{}, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.list.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) add(key string, value interface{}) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.list.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.list.PushFront(&lruEntry{key: key, value: value})
+	if c.list.Len() > c.size {
+		e := c.list.Back()
+		c.list.Remove(e)
+		delete(c.items, e.Value.(*lruEntry).key)
+	}
+}
+
+// removeFunc removes all entries for which fn returns true
+func (c *lruCache) removeFunc(fn func(key string, value interface{}) bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for e := c.list.Front(); e != nil; {
+		next := e.Next()
+		if ent := e.Value.(*lruEntry); fn(ent.key, ent.value) {
+			c.list.Remove(e)
+			delete(c.items, ent.key)
+		}
+		e = next
+	}
+}
+
+func (c *lruCache) len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.list.Len()
+}
@@ -0,0 +1,44 @@
+package tezos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", "1")
+	c.add("b", "2")
+
+	v, ok := c.get("a")
+	require.True(t, ok)
+	require.Equal(t, "1", v)
+
+	// b is the least recently used one
+	c.add("c", "3")
+	require.Equal(t, 2, c.len())
+
+	_, ok = c.get("b")
+	require.False(t, ok)
+
+	v, ok = c.get("c")
+	require.True(t, ok)
+	require.Equal(t, "3", v)
+}
+
+func TestLRUCacheRemoveFunc(t *testing.T) {
+	c := newLRUCache(3)
+	c.add("a", 1)
+	c.add("b", 2)
+	c.add("c", 3)
+
+	c.removeFunc(func(key string, value interface{}) bool { return value.(int) >= 2 })
+	require.Equal(t, 1, c.len())
+
+	_, ok := c.get("b")
+	require.False(t, ok)
+	v, ok := c.get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+}
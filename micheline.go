@@ -0,0 +1,487 @@
+package tezos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// Micheline binary node tags
+const (
+	michelineTagInt           = 0x00
+	michelineTagString        = 0x01
+	michelineTagSeq           = 0x02
+	michelineTagPrim0         = 0x03
+	michelineTagPrim0Annots   = 0x04
+	michelineTagPrim1         = 0x05
+	michelineTagPrim1Annots   = 0x06
+	michelineTagPrim2         = 0x07
+	michelineTagPrim2Annots   = 0x08
+	michelineTagPrimGeneric   = 0x09
+	michelineTagBytes         = 0x0a
+	michelinePackedDataPrefix = 0x05
+)
+
+var michelinePrimitives = []string{
+	"parameter", "storage", "code", "False", "Elt", "Left", "None", "Pair",
+	"Right", "Some", "True", "Unit", "PACK", "UNPACK", "BLAKE2B", "SHA256",
+	"SHA512", "ABS", "ADD", "AMOUNT", "AND", "BALANCE", "CAR", "CDR",
+	"CHECK_SIGNATURE", "COMPARE", "CONCAT", "CONS", "CREATE_ACCOUNT", "CREATE_CONTRACT", "IMPLICIT_ACCOUNT", "DIP",
+	"DROP", "DUP", "EDIV", "EMPTY_MAP", "EMPTY_SET", "EQ", "EXEC", "FAILWITH",
+	"GE", "GET", "GT", "HASH_KEY", "IF", "IF_CONS", "IF_LEFT", "IF_NONE",
+	"INT", "LAMBDA", "LE", "LEFT", "LOOP", "LSL", "LSR", "LT",
+	"MAP", "MEM", "MUL", "NEG", "NEQ", "NIL", "NONE", "NOT",
+	"NOW", "OR", "PAIR", "PUSH", "RIGHT", "SIZE", "SOME", "SOURCE",
+	"SENDER", "SELF", "STEPS_TO_QUOTA", "SUB", "SWAP", "TRANSFER_TOKENS", "SET_DELEGATE", "UNIT",
+	"UPDATE", "XOR", "ITER", "LOOP_LEFT", "ADDRESS", "CONTRACT", "ISNAT", "CAST",
+	"RENAME", "bool", "contract", "int", "key", "key_hash", "lambda", "list",
+	"map", "big_map", "nat", "option", "or", "pair", "set", "signature",
+	"string", "bytes", "mutez", "timestamp", "unit", "operation", "address", "SLICE",
+	"DIG", "DUG", "EMPTY_BIG_MAP", "APPLY", "chain_id", "CHAIN_ID", "LEVEL", "SELF_ADDRESS",
+	"never", "NEVER", "UNPAIR", "VOTING_POWER", "TOTAL_VOTING_POWER", "KECCAK", "SHA3", "PAIRING_CHECK",
+	"bls12_381_g1", "bls12_381_g2", "bls12_381_fr", "sapling_state", "sapling_transaction", "SAPLING_EMPTY_STATE", "SAPLING_VERIFY_UPDATE", "ticket",
+	"TICKET", "READ_TICKET", "SPLIT_TICKET", "JOIN_TICKETS", "GET_AND_UPDATE",
+}
+
+var michelinePrimitiveIndex = func() map[string]int {
+	idx := make(map[string]int, len(michelinePrimitives))
+	for i, p := range michelinePrimitives {
+		idx[p] = i
+	}
+	return idx
+}()
+
+// PackMicheline returns the binary representation of a Micheline expression in JSON form
+// prefixed with 0x05, i.e. the same value the PACK instruction produces for optimized data.
+// The expression is expected to be decoded by encoding/json into interface{}.
+func PackMicheline(v interface{}) (HexBytes, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Micheline
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return m.Pack()
+}
+
+// Pack returns the binary representation of the expression prefixed with 0x05
+func (m Micheline) Pack() (HexBytes, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(michelinePackedDataPrefix)
+	if err := m.encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode returns the binary representation of the expression without a prefix
+func (m Micheline) Encode() (HexBytes, error) {
+	var buf bytes.Buffer
+	if err := m.encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMichelineLen(buf *bytes.Buffer, n int) {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(n))
+	buf.Write(l[:])
+}
+
+func encodeMichelineInt(buf *bytes.Buffer, x *big.Int) {
+	v := new(big.Int).Abs(x)
+	b := byte(new(big.Int).And(v, big.NewInt(0x3f)).Uint64())
+	if x.Sign() < 0 {
+		b |= 0x40
+	}
+	v.Rsh(v, 6)
+	mask := big.NewInt(0x7f)
+	for v.Sign() != 0 {
+		buf.WriteByte(b | 0x80)
+		b = byte(new(big.Int).And(v, mask).Uint64())
+		v.Rsh(v, 7)
+	}
+	buf.WriteByte(b)
+}
+
+func encodeMichelineSeq(buf *bytes.Buffer, items []*Micheline) error {
+	var tmp bytes.Buffer
+	for _, item := range items {
+		if item == nil {
+			return fmt.Errorf("tezos: nil Micheline node")
+		}
+		if err := item.encode(&tmp); err != nil {
+			return err
+		}
+	}
+	writeMichelineLen(buf, tmp.Len())
+	buf.Write(tmp.Bytes())
+	return nil
+}
+
+func (m *Micheline) encode(buf *bytes.Buffer) error {
+	switch m.Kind {
+	case MichelineSeq:
+		buf.WriteByte(michelineTagSeq)
+		return encodeMichelineSeq(buf, m.Seq)
+
+	case MichelineInt:
+		buf.WriteByte(michelineTagInt)
+		if m.Int == nil {
+			encodeMichelineInt(buf, new(big.Int))
+		} else {
+			encodeMichelineInt(buf, m.Int)
+		}
+		return nil
+
+	case MichelineString:
+		buf.WriteByte(michelineTagString)
+		writeMichelineLen(buf, len(m.String))
+		buf.WriteString(m.String)
+		return nil
+
+	case MichelineBytes:
+		buf.WriteByte(michelineTagBytes)
+		writeMichelineLen(buf, len(m.Bytes))
+		buf.Write(m.Bytes)
+		return nil
+
+	case MichelinePrim:
+		prim, ok := michelinePrimitiveIndex[m.Prim]
+		if !ok {
+			return fmt.Errorf("tezos: unknown Micheline primitive: %q", m.Prim)
+		}
+		annots := strings.Join(m.Annots, " ")
+
+		if len(m.Args) > 2 {
+			buf.WriteByte(michelineTagPrimGeneric)
+			buf.WriteByte(byte(prim))
+			if err := encodeMichelineSeq(buf, m.Args); err != nil {
+				return err
+			}
+			writeMichelineLen(buf, len(annots))
+			buf.WriteString(annots)
+			return nil
+		}
+
+		tag := michelineTagPrim0 + 2*len(m.Args)
+		if len(m.Annots) != 0 {
+			tag++
+		}
+		buf.WriteByte(byte(tag))
+		buf.WriteByte(byte(prim))
+		for _, arg := range m.Args {
+			if arg == nil {
+				return fmt.Errorf("tezos: nil Micheline node")
+			}
+			if err := arg.encode(buf); err != nil {
+				return err
+			}
+		}
+		if len(m.Annots) != 0 {
+			writeMichelineLen(buf, len(annots))
+			buf.WriteString(annots)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("tezos: unknown Micheline node kind: %d", m.Kind)
+}
+
+var errMichelineTruncated = errors.New("tezos: truncated Micheline expression")
+
+// UnpackMicheline decodes the binary representation of an expression prefixed with 0x05
+func UnpackMicheline(b HexBytes) (Micheline, error) {
+	if len(b) == 0 || b[0] != michelinePackedDataPrefix {
+		return Micheline{}, fmt.Errorf("tezos: packed data must start with 0x%02x", michelinePackedDataPrefix)
+	}
+	return DecodeMicheline(b[1:])
+}
+
+// DecodeMicheline decodes the binary representation of an expression without a prefix
+func DecodeMicheline(b HexBytes) (Micheline, error) {
+	r := bytes.NewReader(b)
+	m, err := decodeMicheline(r)
+	if err != nil {
+		return Micheline{}, err
+	}
+	if r.Len() != 0 {
+		return Micheline{}, fmt.Errorf("tezos: %d trailing bytes after Micheline expression", r.Len())
+	}
+	return *m, nil
+}
+
+func readMichelineBytes(r *bytes.Reader) ([]byte, error) {
+	var l [4]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, errMichelineTruncated
+	}
+	n := binary.BigEndian.Uint32(l[:])
+	if uint64(n) > uint64(r.Len()) {
+		return nil, errMichelineTruncated
+	}
+	buf := make([]byte, n)
+	io.ReadFull(r, buf)
+	return buf, nil
+}
+
+func decodeMichelineInt(r *bytes.Reader) (*big.Int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, errMichelineTruncated
+	}
+	neg := b&0x40 != 0
+	x := big.NewInt(int64(b & 0x3f))
+	shift := uint(6)
+	for b&0x80 != 0 {
+		if b, err = r.ReadByte(); err != nil {
+			return nil, errMichelineTruncated
+		}
+		x.Or(x, new(big.Int).Lsh(big.NewInt(int64(b&0x7f)), shift))
+		shift += 7
+	}
+	if neg {
+		x.Neg(x)
+	}
+	return x, nil
+}
+
+func decodeMichelineSeq(r *bytes.Reader) ([]*Micheline, error) {
+	data, err := readMichelineBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	sr := bytes.NewReader(data)
+	items := []*Micheline{}
+	for sr.Len() != 0 {
+		item, err := decodeMicheline(sr)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func decodeMichelineAnnots(r *bytes.Reader) ([]string, error) {
+	data, err := readMichelineBytes(r)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	return strings.Split(string(data), " "), nil
+}
+
+func decodeMicheline(r *bytes.Reader) (*Micheline, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, errMichelineTruncated
+	}
+
+	switch tag {
+	case michelineTagInt:
+		x, err := decodeMichelineInt(r)
+		if err != nil {
+			return nil, err
+		}
+		return &Micheline{Kind: MichelineInt, Int: x}, nil
+
+	case michelineTagString:
+		s, err := readMichelineBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		return &Micheline{Kind: MichelineString, String: string(s)}, nil
+
+	case michelineTagBytes:
+		b, err := readMichelineBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		return &Micheline{Kind: MichelineBytes, Bytes: b}, nil
+
+	case michelineTagSeq:
+		seq, err := decodeMichelineSeq(r)
+		if err != nil {
+			return nil, err
+		}
+		return &Micheline{Kind: MichelineSeq, Seq: seq}, nil
+
+	case michelineTagPrim0, michelineTagPrim0Annots, michelineTagPrim1, michelineTagPrim1Annots,
+		michelineTagPrim2, michelineTagPrim2Annots, michelineTagPrimGeneric:
+		p, err := r.ReadByte()
+		if err != nil {
+			return nil, errMichelineTruncated
+		}
+		if int(p) >= len(michelinePrimitives) {
+			return nil, fmt.Errorf("tezos: unknown Micheline primitive: 0x%02x", p)
+		}
+		m := Micheline{Kind: MichelinePrim, Prim: michelinePrimitives[p]}
+
+		if tag == michelineTagPrimGeneric {
+			if m.Args, err = decodeMichelineSeq(r); err != nil {
+				return nil, err
+			}
+			if m.Annots, err = decodeMichelineAnnots(r); err != nil {
+				return nil, err
+			}
+			return &m, nil
+		}
+
+		nargs := int(tag-michelineTagPrim0) / 2
+		for i := 0; i < nargs; i++ {
+			arg, err := decodeMicheline(r)
+			if err != nil {
+				return nil, err
+			}
+			m.Args = append(m.Args, arg)
+		}
+		if (tag-michelineTagPrim0)%2 == 1 {
+			if m.Annots, err = decodeMichelineAnnots(r); err != nil {
+				return nil, err
+			}
+		}
+		return &m, nil
+	}
+
+	return nil, fmt.Errorf("tezos: unknown Micheline node tag: 0x%02x", tag)
+}
+
+// MichelineKind is a kind of the Micheline node
+type MichelineKind int
+
+// Micheline node kinds
+const (
+	MichelinePrim MichelineKind = iota
+	MichelineInt
+	MichelineString
+	MichelineBytes
+	MichelineSeq
+)
+
+// Micheline is a node of a Micheline expression. Only the fields corresponding to Kind are used.
+type Micheline struct {
+	Kind   MichelineKind
+	Prim   string
+	Args   []*Micheline
+	Annots []string
+	Int    *big.Int
+	String string
+	Bytes  HexBytes
+	Seq    []*Micheline
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *Micheline) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) != 0 && data[0] == '[' {
+		var seq []*Micheline
+		if err := json.Unmarshal(data, &seq); err != nil {
+			return err
+		}
+		*m = Micheline{Kind: MichelineSeq, Seq: seq}
+		return nil
+	}
+
+	var tmp struct {
+		Prim   *string      `json:"prim"`
+		Args   []*Micheline `json:"args"`
+		Annots []string     `json:"annots"`
+		Int    *string      `json:"int"`
+		String *string      `json:"string"`
+		Bytes  *HexBytes    `json:"bytes"`
+	}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	switch {
+	case tmp.Prim != nil:
+		*m = Micheline{Kind: MichelinePrim, Prim: *tmp.Prim, Args: tmp.Args, Annots: tmp.Annots}
+	case tmp.Int != nil:
+		x, ok := new(big.Int).SetString(*tmp.Int, 10)
+		if !ok {
+			return fmt.Errorf("tezos: invalid Micheline int: %q", *tmp.Int)
+		}
+		*m = Micheline{Kind: MichelineInt, Int: x}
+	case tmp.String != nil:
+		*m = Micheline{Kind: MichelineString, String: *tmp.String}
+	case tmp.Bytes != nil:
+		*m = Micheline{Kind: MichelineBytes, Bytes: *tmp.Bytes}
+	default:
+		return fmt.Errorf("tezos: invalid Micheline node: %s", data)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *Micheline) MarshalJSON() ([]byte, error) {
+	switch m.Kind {
+	case MichelinePrim:
+		v := struct {
+			Prim   string       `json:"prim"`
+			Args   []*Micheline `json:"args,omitempty"`
+			Annots []string     `json:"annots,omitempty"`
+		}{
+			Prim:   m.Prim,
+			Args:   m.Args,
+			Annots: m.Annots,
+		}
+		return json.Marshal(&v)
+
+	case MichelineInt:
+		if m.Int == nil {
+			return []byte(`{"int":"0"}`), nil
+		}
+		return json.Marshal(map[string]string{"int": m.Int.String()})
+
+	case MichelineString:
+		return json.Marshal(map[string]string{"string": m.String})
+
+	case MichelineBytes:
+		return json.Marshal(map[string]string{"bytes": hex.EncodeToString(m.Bytes)})
+
+	case MichelineSeq:
+		if m.Seq == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(m.Seq)
+	}
+
+	return nil, fmt.Errorf("tezos: unknown Micheline node kind: %d", m.Kind)
+}
+
+// MarshalYAML implements yaml.Marshaler. The node is rendered the same way as its JSON representation
+func (m *Micheline) MarshalYAML() (interface{}, error) {
+	buf, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func unmarshalMichelineField(data json.RawMessage) (*Micheline, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var m Micheline
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
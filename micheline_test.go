@@ -0,0 +1,185 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPackMicheline(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: `{"int":"1"}`, expected: "050001"},
+		{expr: `{"int":"-64"}`, expected: "0500c001"},
+		{expr: `{"int":"64"}`, expected: "05008001"},
+		{expr: `{"string":"foo"}`, expected: "050100000003666f6f"},
+		{expr: `{"bytes":"cafe"}`, expected: "050a00000002cafe"},
+		{expr: `{"prim":"Pair","args":[{"int":"1"},{"prim":"Pair","args":[{"string":"a"},{"prim":"Unit"}]}]}`, expected: "05070700010707010000000161030b"},
+		{expr: `[{"int":"1"},{"int":"2"}]`, expected: "05020000000400010002"},
+		{expr: `{"prim":"pair","args":[{"prim":"nat","annots":["%n"]},{"prim":"unit"}]}`, expected: "050765046200000002256e036c"},
+	}
+
+	for _, c := range cases {
+		var v interface{}
+		require.NoError(t, json.Unmarshal([]byte(c.expr), &v))
+		packed, err := PackMicheline(v)
+		require.NoError(t, err, c.expr)
+		expected, err := hex.DecodeString(c.expected)
+		require.NoError(t, err)
+		require.Equal(t, HexBytes(expected), packed, c.expr)
+	}
+}
+
+func TestMichelineJSON(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/origination_code.json")
+	require.NoError(t, err)
+
+	var ops []struct {
+		Contents []struct {
+			Script *struct {
+				Code    json.RawMessage `json:"code"`
+				Storage json.RawMessage `json:"storage"`
+			} `json:"script"`
+		} `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(data, &ops))
+	script := ops[0].Contents[0].Script
+
+	for _, src := range []json.RawMessage{script.Code, script.Storage, json.RawMessage(`{"bytes":"cafe"}`), json.RawMessage(`{"string":"foo"}`)} {
+		var m Micheline
+		require.NoError(t, json.Unmarshal(src, &m))
+		out, err := json.Marshal(&m)
+		require.NoError(t, err)
+		require.JSONEq(t, string(src), string(out))
+	}
+
+	var code Micheline
+	require.NoError(t, json.Unmarshal(script.Code, &code))
+	require.Equal(t, MichelineSeq, code.Kind)
+	require.Len(t, code.Seq, 3)
+	require.Equal(t, "parameter", code.Seq[0].Prim)
+	require.Equal(t, []string{"%increment"}, code.Seq[0].Args[0].Args[1].Annots)
+
+	var m Micheline
+	require.Error(t, json.Unmarshal([]byte(`{"foo":"bar"}`), &m))
+	require.Error(t, json.Unmarshal([]byte(`{"int":"x"}`), &m))
+}
+
+func TestDecodeOptionsMicheline(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/origination_code.json")
+	require.NoError(t, err)
+
+	ops, err := UnmarshalOperationsForProtocol(data, nil)
+	require.NoError(t, err)
+	orig := ops[0].Contents[0].(*OriginationOperationElem)
+	require.NotNil(t, orig.Script)
+	require.Equal(t, MichelineSeq, orig.Script.Code.Kind)
+	require.Len(t, orig.Script.Code.Seq, 3)
+	require.Equal(t, &Micheline{Kind: MichelineInt, Int: big.NewInt(-42)}, orig.Script.Storage)
+	tx := ops[0].Contents[1].(*TransactionOperationElem)
+	require.Nil(t, tx.ParametersMicheline)
+
+	ops, err = UnmarshalOperationsForProtocol(data, &DecodeOptions{Micheline: true})
+	require.NoError(t, err)
+	tx = ops[0].Contents[1].(*TransactionOperationElem)
+	require.Equal(t, &Micheline{Kind: MichelineInt, Int: big.NewInt(5)}, tx.ParametersMicheline)
+}
+
+func TestScriptedContractsRoundTrip(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/origination_code.json")
+	require.NoError(t, err)
+
+	var src []struct {
+		Contents []struct {
+			Script json.RawMessage `json:"script"`
+		} `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(data, &src))
+
+	var ops []*Operation
+	require.NoError(t, json.Unmarshal(data, &ops))
+	orig := ops[0].Contents[0].(*OriginationOperationElem)
+
+	out, err := json.Marshal(orig.Script)
+	require.NoError(t, err)
+	require.JSONEq(t, string(src[0].Contents[0].Script), string(out))
+}
+
+func TestMichelinePackUnpack(t *testing.T) {
+	cases := []struct {
+		expr     Micheline
+		expected string
+	}{
+		{expr: Micheline{Kind: MichelineInt, Int: big.NewInt(1)}, expected: "050001"},
+		{expr: Micheline{Kind: MichelineInt, Int: big.NewInt(-64)}, expected: "0500c001"},
+		{expr: Micheline{Kind: MichelineInt, Int: big.NewInt(1000000)}, expected: "050080897a"},
+		{expr: Micheline{Kind: MichelineString, String: "foo"}, expected: "050100000003666f6f"},
+		{expr: Micheline{Kind: MichelineBytes, Bytes: HexBytes{0xca, 0xfe}}, expected: "050a00000002cafe"},
+		{
+			expr: Micheline{Kind: MichelinePrim, Prim: "Pair", Args: []*Micheline{
+				&Micheline{Kind: MichelineInt, Int: big.NewInt(1)},
+				&Micheline{Kind: MichelinePrim, Prim: "Pair", Args: []*Micheline{
+					&Micheline{Kind: MichelineString, String: "a"},
+					&Micheline{Kind: MichelinePrim, Prim: "Unit"},
+				}},
+			}},
+			expected: "05070700010707010000000161030b",
+		},
+		{
+			expr: Micheline{Kind: MichelineSeq, Seq: []*Micheline{
+				&Micheline{Kind: MichelinePrim, Prim: "nat", Annots: []string{"%n"}},
+				&Micheline{Kind: MichelinePrim, Prim: "IF", Args: []*Micheline{
+					&Micheline{Kind: MichelineSeq, Seq: []*Micheline{}},
+					&Micheline{Kind: MichelineSeq, Seq: []*Micheline{}},
+				}},
+				&Micheline{Kind: MichelinePrim, Prim: "Elt", Args: []*Micheline{
+					&Micheline{Kind: MichelineInt, Int: big.NewInt(0)},
+					&Micheline{Kind: MichelineInt, Int: big.NewInt(0)},
+					&Micheline{Kind: MichelineInt, Int: big.NewInt(0)},
+				}, Annots: []string{"@a", "@b"}},
+			}},
+			expected: "050200000029046200000002256e072c02000000000200000000090400000006000000000000000000054061204062",
+		},
+	}
+
+	for _, c := range cases {
+		packed, err := c.expr.Pack()
+		require.NoError(t, err)
+		expected, err := hex.DecodeString(c.expected)
+		require.NoError(t, err)
+		require.Equal(t, HexBytes(expected), packed)
+
+		unpacked, err := UnpackMicheline(packed)
+		require.NoError(t, err)
+		require.Equal(t, c.expr, unpacked)
+
+		raw, err := c.expr.Encode()
+		require.NoError(t, err)
+		require.Equal(t, packed[1:], raw)
+		decoded, err := DecodeMicheline(raw)
+		require.NoError(t, err)
+		require.Equal(t, c.expr, decoded)
+	}
+
+	_, err := UnpackMicheline(HexBytes{0x00, 0x00, 0x01})
+	require.Error(t, err)
+	_, err = UnpackMicheline(HexBytes{0x05, 0x01, 0x00, 0x00, 0x00, 0x05, 0x61})
+	require.Error(t, err)
+	_, err = UnpackMicheline(HexBytes{0x05, 0x00, 0x01, 0x00})
+	require.Error(t, err)
+}
+
+func TestMichelineYAML(t *testing.T) {
+	var m Micheline
+	require.NoError(t, json.Unmarshal([]byte(`[{"prim":"PUSH","args":[{"prim":"nat"},{"int":"1"}]},{"prim":"DROP"}]`), &m))
+	buf, err := yaml.Marshal(&m)
+	require.NoError(t, err)
+	require.YAMLEq(t, `[{prim: PUSH, args: [{prim: nat}, {int: "1"}]}, {prim: DROP}]`, string(buf))
+}
@@ -0,0 +1,99 @@
+package tezos
+
+import (
+	"bytes"
+	"encoding/hex"
+)
+
+// MultisigAction is an action of the generic multisig contract
+type MultisigAction interface {
+	multisigAction() (interface{}, error)
+}
+
+// MultisigTransfer is a MultisigAction transferring Amount mutez to Destination
+type MultisigTransfer struct {
+	Amount      *BigInt
+	Destination string
+}
+
+// MultisigSetDelegate is a MultisigAction changing the contract's delegate. Empty Delegate withdraws the delegation
+type MultisigSetDelegate struct {
+	Delegate string
+}
+
+func michelinePrim(prim string, args ...interface{}) map[string]interface{} {
+	v := map[string]interface{}{"prim": prim}
+	if len(args) != 0 {
+		v["args"] = args
+	}
+	return v
+}
+
+func michelineInteger(x *BigInt) map[string]interface{} {
+	if x == nil {
+		return map[string]interface{}{"int": "0"}
+	}
+	return map[string]interface{}{"int": x.String()}
+}
+
+// michelineOptimizedBytes returns the optimized representation of an address or key hash
+func michelineOptimizedBytes(forge func(*bytes.Buffer, string) error, s string) (map[string]interface{}, error) {
+	var buf bytes.Buffer
+	if err := forge(&buf, s); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"bytes": hex.EncodeToString(buf.Bytes())}, nil
+}
+
+func (m *MultisigTransfer) multisigAction() (interface{}, error) {
+	dest, err := michelineOptimizedBytes(forgeContractID, m.Destination)
+	if err != nil {
+		return nil, err
+	}
+	return michelinePrim("Left", michelinePrim("Pair", michelineInteger(m.Amount), dest)), nil
+}
+
+func (m *MultisigSetDelegate) multisigAction() (interface{}, error) {
+	if m.Delegate == "" {
+		return michelinePrim("Right", michelinePrim("Left", michelinePrim("None"))), nil
+	}
+	pkh, err := michelineOptimizedBytes(forgePublicKeyHash, m.Delegate)
+	if err != nil {
+		return nil, err
+	}
+	return michelinePrim("Right", michelinePrim("Left", michelinePrim("Some", pkh))), nil
+}
+
+// michelineChainID returns the optimized representation of a chain id
+func michelineChainID(chainID string) (map[string]interface{}, error) {
+	data, err := base58CheckDecode(chainID, prefixChainID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"bytes": hex.EncodeToString(data)}, nil
+}
+
+// BuildMultisigPayload returns the packed data to be signed by the generic multisig contract owners,
+// i.e. Pair (Pair chain_id contract) (Pair counter action)
+func BuildMultisigPayload(chainID, contract string, counter *BigInt, action MultisigAction) (HexBytes, error) {
+	chain, err := michelineChainID(chainID)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := michelineOptimizedBytes(forgeContractID, contract)
+	if err != nil {
+		return nil, err
+	}
+	act, err := action.multisigAction()
+	if err != nil {
+		return nil, err
+	}
+	return PackMicheline(michelinePrim("Pair",
+		michelinePrim("Pair", chain, addr),
+		michelinePrim("Pair", michelineInteger(counter), act)))
+}
+
+var (
+	_ MultisigAction = &MultisigTransfer{}
+	_ MultisigAction = &MultisigSetDelegate{}
+)
@@ -0,0 +1,43 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMultisigPayload(t *testing.T) {
+	cases := []struct {
+		counter  string
+		action   MultisigAction
+		expected string
+	}{
+		{
+			counter:  "42",
+			action:   &MultisigTransfer{Amount: bigIntMust("1000000"), Destination: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"},
+			expected: "05070707070a000000047a06a7700a000000160158d5190653b78ef76f185ef784afe9488f39eda5000707002a050507070080897a0a00000016000002298c03ed7d454a101eb7022bc95f7e5f41ac78",
+		},
+		{
+			counter:  "42",
+			action:   &MultisigSetDelegate{Delegate: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"},
+			expected: "05070707070a000000047a06a7700a000000160158d5190653b78ef76f185ef784afe9488f39eda5000707002a0508050505090a000000150002298c03ed7d454a101eb7022bc95f7e5f41ac78",
+		},
+		{
+			counter:  "43",
+			action:   &MultisigSetDelegate{},
+			expected: "05070707070a000000047a06a7700a000000160158d5190653b78ef76f185ef784afe9488f39eda5000707002b050805050306",
+		},
+	}
+
+	for _, c := range cases {
+		payload, err := BuildMultisigPayload("NetXdQprcVkpaWU", "KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo", bigIntMust(c.counter), c.action)
+		require.NoError(t, err)
+		expected, err := hex.DecodeString(c.expected)
+		require.NoError(t, err)
+		require.Equal(t, HexBytes(expected), payload)
+	}
+
+	_, err := BuildMultisigPayload("BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", "KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo", bigIntMust("42"), &MultisigSetDelegate{})
+	require.Error(t, err)
+}
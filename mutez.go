@@ -0,0 +1,115 @@
+package tezos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+// Mutez is an amount in micro-tez
+type Mutez int64
+
+// String renders the amount in tez with six decimal places, e.g. 1.500000
+func (m Mutez) String() string {
+	var (
+		sign string
+		abs  = uint64(m)
+	)
+	if m < 0 {
+		sign = "-"
+		abs = uint64(-(m + 1)) + 1 // MinInt64 safe
+	}
+	return fmt.Sprintf("%s%d.%06d", sign, abs/1000000, abs%1000000)
+}
+
+var tezRegexp = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+var errMutezRange = errors.New("tezos: amount is out of range")
+
+// ParseMutez parses an amount in tez, e.g. 1.5. Digits beyond the sixth decimal place are rounded half away from zero.
+func ParseMutez(s string) (Mutez, error) {
+	if !tezRegexp.MatchString(s) {
+		return 0, fmt.Errorf("tezos: invalid tez amount: %q", s)
+	}
+
+	var r big.Rat
+	if _, ok := r.SetString(s); !ok {
+		return 0, fmt.Errorf("tezos: invalid tez amount: %q", s)
+	}
+	r.Mul(&r, big.NewRat(1000000, 1))
+
+	// Round half away from zero
+	num := new(big.Int).Abs(r.Num())
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+
+	if !q.IsInt64() {
+		return 0, errMutezRange
+	}
+	return Mutez(q.Int64()), nil
+}
+
+// MarshalJSON implements json.Marshaler. The amount is encoded as a quoted integer like the node does.
+func (m Mutez) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(m), 10))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Both quoted and bare integers are accepted.
+func (m *Mutez) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if len(data) != 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(data)
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*m = Mutez(v)
+	return nil
+}
+
+// Mutez converts the value to Mutez
+func (z *BigInt) Mutez() (Mutez, error) {
+	if z == nil {
+		return 0, nil
+	}
+	v, ok := z.Int64()
+	if !ok {
+		return 0, errMutezRange
+	}
+	return Mutez(v), nil
+}
+
+// FeeMutez returns the fee of the operation as Mutez
+func FeeMutez(op OperationWithFee) (Mutez, error) {
+	fee := op.OperationFee()
+	if !fee.IsInt64() {
+		return 0, errMutezRange
+	}
+	return Mutez(fee.Int64()), nil
+}
+
+// Tez returns the exact amount in tez assuming the value is in mutez
+func (z *BigInt) Tez() *big.Rat {
+	if z == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).SetFrac(&z.Int, big.NewInt(1000000))
+}
@@ -0,0 +1,115 @@
+package tezos
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutezString(t *testing.T) {
+	tests := []struct {
+		v        Mutez
+		expected string
+	}{
+		{0, "0.000000"},
+		{1, "0.000001"},
+		{1500000, "1.500000"},
+		{-1500000, "-1.500000"},
+		{-1, "-0.000001"},
+		{math.MaxInt64, "9223372036854.775807"},
+		{math.MinInt64, "-9223372036854.775808"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, test.v.String())
+	}
+}
+
+func TestParseMutez(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected Mutez
+		err      bool
+	}{
+		{src: "0", expected: 0},
+		{src: "0.000000", expected: 0},
+		{src: "-0", expected: 0},
+		{src: "1.5", expected: 1500000},
+		{src: "-1.5", expected: -1500000},
+		{src: "+2", expected: 2000000},
+		{src: "0.0000004", expected: 0},
+		{src: "0.0000005", expected: 1},
+		{src: "-0.0000005", expected: -1},
+		{src: "1.2345675", expected: 1234568},
+		{src: "9223372036854.775807", expected: math.MaxInt64},
+		{src: "9223372036854.775808", err: true},
+		{src: "1e6", err: true},
+		{src: "1/2", err: true},
+		{src: ".5", err: true},
+		{src: "", err: true},
+	}
+
+	for _, test := range tests {
+		v, err := ParseMutez(test.src)
+		if test.err {
+			require.Error(t, err, test.src)
+			continue
+		}
+		require.NoError(t, err, test.src)
+		require.Equal(t, test.expected, v, test.src)
+	}
+}
+
+func TestMutezJSON(t *testing.T) {
+	var v struct {
+		Amount  Mutez `json:"amount"`
+		Balance Mutez `json:"balance"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"-1500000","balance":0}`), &v))
+	require.Equal(t, Mutez(-1500000), v.Amount)
+	require.Equal(t, Mutez(0), v.Balance)
+
+	buf, err := json.Marshal(&v)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":"-1500000","balance":"0"}`, string(buf))
+
+	m, err := bigIntMust("1500000").Mutez()
+	require.NoError(t, err)
+	require.Equal(t, "1.500000", m.String())
+
+	_, err = bigIntMust("9223372036854775808").Mutez()
+	require.Error(t, err)
+}
+
+func TestBigIntTez(t *testing.T) {
+	require.Equal(t, "4700354.460878", bigIntMust("4700354460878").Tez().FloatString(6))
+	require.Equal(t, "3/2", bigIntMust("1500000").Tez().String())
+	require.Equal(t, "0/1", (*BigInt)(nil).Tez().String())
+}
+
+func TestOperationMutez(t *testing.T) {
+	var elems OperationElements
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"kind": "transaction", "fee": "1420", "amount": "1500000"},
+		{"kind": "origination", "fee": "1400", "balance": "250000"}
+	]`), &elems))
+
+	tx := elems[0].(*TransactionOperationElem)
+	amount, err := tx.AmountMutez()
+	require.NoError(t, err)
+	require.Equal(t, Mutez(1500000), amount)
+	fee, err := FeeMutez(tx)
+	require.NoError(t, err)
+	require.Equal(t, "0.001420", fee.String())
+
+	orig := elems[1].(*OriginationOperationElem)
+	balance, err := orig.BalanceMutez()
+	require.NoError(t, err)
+	require.Equal(t, "0.250000", balance.String())
+
+	amount, err = (&Transfer{Amount: bigIntMust("42")}).AmountMutez()
+	require.NoError(t, err)
+	require.Equal(t, Mutez(42), amount)
+}
@@ -2,7 +2,9 @@ package tezos
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"sort"
 )
 
 // OperationElem must be implemented by all operation elements
@@ -30,6 +32,20 @@ func (e *GenericOperationElem) OperationElemKind() string {
 	return e.Kind
 }
 
+// RawOperationElem is an element of a kind unknown to the library. It retains the original JSON so newer operation kinds can still be inspected
+type RawOperationElem struct {
+	GenericOperationElem `yaml:",inline"`
+	Raw                  json.RawMessage `json:"-" yaml:"-"`
+}
+
+// MarshalJSON implements json.Marshaler by re-emitting the original JSON
+func (e *RawOperationElem) MarshalJSON() ([]byte, error) {
+	if e.Raw == nil {
+		return json.Marshal(&e.GenericOperationElem)
+	}
+	return e.Raw, nil
+}
+
 // OperationElements is a slice of OperationElem with custom JSON unmarshaller
 type OperationElements []OperationElem
 
@@ -45,14 +61,16 @@ func (e *OperationElements) UnmarshalJSON(data []byte) error {
 
 opLoop:
 	for i, r := range raw {
-		var tmp GenericOperationElem
-		if err := json.Unmarshal(r, &tmp); err != nil {
+		var tmp RawOperationElem
+		if err := json.Unmarshal(r, &tmp.GenericOperationElem); err != nil {
 			return err
 		}
 
 		switch tmp.Kind {
-		case "endorsement":
+		case "endorsement", "attestation_with_dal":
 			(*e)[i] = &EndorsementOperationElem{}
+		case "endorsement_with_slot":
+			(*e)[i] = &EndorsementWithSlotOperationElem{}
 		case "transaction":
 			(*e)[i] = &TransactionOperationElem{}
 		case "ballot":
@@ -73,7 +91,14 @@ opLoop:
 			(*e)[i] = &OriginationOperationElem{}
 		case "delegation":
 			(*e)[i] = &DelegationOperationElem{}
+		case "dal_publish_slot_header":
+			(*e)[i] = &DALPublishSlotHeaderOperationElem{}
+		case "register_global_constant":
+			(*e)[i] = &RegisterGlobalConstantOperationElem{}
+		case "set_deposits_limit":
+			(*e)[i] = &SetDepositsLimitOperationElem{}
 		default:
+			tmp.Raw = r
 			(*e)[i] = &tmp
 			continue opLoop
 		}
@@ -86,10 +111,30 @@ opLoop:
 	return nil
 }
 
+// MarshalJSON implements json.Marshaler
+func (e OperationElements) MarshalJSON() ([]byte, error) {
+	raw := make([]json.RawMessage, len(e))
+
+	for i, el := range e {
+		if el.OperationElemKind() == "" {
+			return nil, fmt.Errorf("tezos: operation element #%d (%T) has no kind", i, el)
+		}
+
+		buf, err := json.Marshal(el)
+		if err != nil {
+			return nil, err
+		}
+		raw[i] = buf
+	}
+
+	return json.Marshal(raw)
+}
+
 // EndorsementOperationElem represents an endorsement operation
 type EndorsementOperationElem struct {
 	GenericOperationElem `yaml:",inline"`
 	Level                int                          `json:"level" yaml:"level"`
+	DALAttestation       *BigInt                      `json:"dal_attestation,omitempty" yaml:"dal_attestation,omitempty"` // attestation_with_dal only
 	Metadata             EndorsementOperationMetadata `json:"metadata" yaml:"metadata"`
 }
 
@@ -98,11 +143,34 @@ func (el *EndorsementOperationElem) BalanceUpdates() BalanceUpdates {
 	return el.Metadata.BalanceUpdates
 }
 
+// EndorsementWithSlotOperationElem represents an endorsement_with_slot operation used since Florence.
+// It wraps a signed endorsement along with the first of the delegate's slots
+type EndorsementWithSlotOperationElem struct {
+	GenericOperationElem `yaml:",inline"`
+	Endorsement          InlinedEndorsement           `json:"endorsement" yaml:"endorsement"`
+	Slot                 int                          `json:"slot" yaml:"slot"`
+	Metadata             EndorsementOperationMetadata `json:"metadata" yaml:"metadata"`
+}
+
+// BalanceUpdates implements BalanceUpdateOperation
+func (el *EndorsementWithSlotOperationElem) BalanceUpdates() BalanceUpdates {
+	return el.Metadata.BalanceUpdates
+}
+
 // EndorsementOperationMetadata represents an endorsement operation metadata
 type EndorsementOperationMetadata struct {
-	BalanceUpdates BalanceUpdates `json:"balance_updates" yaml:"balance_updates"`
-	Delegate       string         `json:"delegate" yaml:"delegate"`
-	Slots          []int          `json:"slots" yaml:"slots,flow"`
+	BalanceUpdates   BalanceUpdates `json:"balance_updates" yaml:"balance_updates"`
+	Delegate         string         `json:"delegate" yaml:"delegate"`
+	Slots            []int          `json:"slots" yaml:"slots,flow"`
+	EndorsementPower int            `json:"endorsement_power,omitempty" yaml:"endorsement_power,omitempty"` // Tenderbake
+}
+
+// EndorsingPower returns the number of slots or the endorsement power for Tenderbake
+func (m *EndorsementOperationMetadata) EndorsingPower() int {
+	if m.EndorsementPower != 0 {
+		return m.EndorsementPower
+	}
+	return len(m.Slots)
 }
 
 // TransactionOperationElem represents a transaction operation
@@ -116,9 +184,46 @@ type TransactionOperationElem struct {
 	Amount               *BigInt                      `json:"amount" yaml:"amount"`
 	Destination          string                       `json:"destination" yaml:"destination"`
 	Parameters           map[string]interface{}       `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	ParametersMicheline  *Micheline                   `json:"-" yaml:"-"` // parameters value, populated if DecodeOptions.Micheline is set
 	Metadata             TransactionOperationMetadata `json:"metadata" yaml:"metadata"`
 }
 
+// wrappedParameters returns the entrypoint and the value of the post-Babylon parameters
+func (el *TransactionOperationElem) wrappedParameters() (string, interface{}, bool) {
+	entrypoint, ok := el.Parameters["entrypoint"].(string)
+	if !ok {
+		return "", nil, false
+	}
+	value, ok := el.Parameters["value"]
+	return entrypoint, value, ok
+}
+
+// Entrypoint returns the called entrypoint. Pre-Babylon parameters always call the default one.
+// False is returned if the transaction has no parameters.
+func (el *TransactionOperationElem) Entrypoint() (string, bool) {
+	if el.Parameters == nil {
+		return "", false
+	}
+	if entrypoint, _, ok := el.wrappedParameters(); ok {
+		return entrypoint, true
+	}
+	return "default", true
+}
+
+// ParameterValue returns the Micheline value passed to the entrypoint for both post-Babylon {entrypoint, value}
+// and pre-Babylon bare value parameters. False is returned if the transaction has no parameters
+// or the value isn't a Micheline object (e.g. a sequence).
+func (el *TransactionOperationElem) ParameterValue() (map[string]interface{}, bool) {
+	if el.Parameters == nil {
+		return nil, false
+	}
+	if _, value, ok := el.wrappedParameters(); ok {
+		v, ok := value.(map[string]interface{})
+		return v, ok
+	}
+	return el.Parameters, true
+}
+
 // BalanceUpdates implements BalanceUpdateOperation
 func (el *TransactionOperationElem) BalanceUpdates() BalanceUpdates {
 	return el.Metadata.BalanceUpdates
@@ -132,22 +237,284 @@ func (el *TransactionOperationElem) OperationFee() *big.Int {
 	return big.NewInt(0)
 }
 
+// AmountMutez returns the transferred amount as Mutez
+func (el *TransactionOperationElem) AmountMutez() (Mutez, error) {
+	return el.Amount.Mutez()
+}
+
+// EffectiveTransfers returns the transfers which actually took effect, i.e. the operation itself
+// and its internal transactions, excluding failed, skipped and backtracked ones
+func (el *TransactionOperationElem) EffectiveTransfers() []*Transfer {
+	if el.Metadata.OperationResult.Status != "applied" {
+		return nil
+	}
+
+	transfers := []*Transfer{
+		&Transfer{
+			Source:      el.Source,
+			Destination: el.Destination,
+			Amount:      el.Amount,
+		},
+	}
+
+	for _, r := range el.Metadata.InternalOperationResults {
+		if r.Kind != "transaction" || r.Result == nil || r.Result.OperationResultStatus() != "applied" {
+			continue
+		}
+		transfers = append(transfers, &Transfer{
+			Source:      r.Source,
+			Destination: r.Destination,
+			Amount:      r.Amount,
+		})
+	}
+
+	return transfers
+}
+
+// CallNode is a node of the call tree of a transaction
+type CallNode struct {
+	Source      string
+	Destination string
+	Amount      *BigInt
+	Operation   *InternalOperationResult // nil for the root
+	Children    []*CallNode
+}
+
+// CallTree reconstructs the call tree of the transaction from its internal operations.
+// Internal operations are ordered by nonce and each one becomes a child of the latest call to its source.
+func (el *TransactionOperationElem) CallTree() *CallNode {
+	root := &CallNode{
+		Source:      el.Source,
+		Destination: el.Destination,
+		Amount:      el.Amount,
+	}
+
+	ops := make([]*InternalOperationResult, len(el.Metadata.InternalOperationResults))
+	copy(ops, el.Metadata.InternalOperationResults)
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].Nonce < ops[j].Nonce })
+
+	nodes := []*CallNode{root}
+	for _, op := range ops {
+		n := &CallNode{
+			Source:      op.Source,
+			Destination: op.Destination,
+			Amount:      op.Amount,
+			Operation:   op,
+		}
+
+		parent := root
+		for i := len(nodes) - 1; i >= 0; i-- {
+			if nodes[i].Destination == op.Source {
+				parent = nodes[i]
+				break
+			}
+		}
+		parent.Children = append(parent.Children, n)
+		nodes = append(nodes, n)
+	}
+
+	return root
+}
+
+// Transfer represents a tez transfer between two contracts
+type Transfer struct {
+	Source      string  `json:"source" yaml:"source"`
+	Destination string  `json:"destination" yaml:"destination"`
+	Amount      *BigInt `json:"amount" yaml:"amount"`
+}
+
+// AmountMutez returns the transferred amount as Mutez
+func (t *Transfer) AmountMutez() (Mutez, error) {
+	return t.Amount.Mutez()
+}
+
 // TransactionOperationMetadata represents a transaction operation metadata
 type TransactionOperationMetadata struct {
-	BalanceUpdates  BalanceUpdates             `json:"balance_updates" yaml:"balance_updates"`
-	OperationResult TransactionOperationResult `json:"operation_result" yaml:"operation_result"`
+	BalanceUpdates           BalanceUpdates             `json:"balance_updates" yaml:"balance_updates"`
+	OperationResult          TransactionOperationResult `json:"operation_result" yaml:"operation_result"`
+	InternalOperationResults []*InternalOperationResult `json:"internal_operation_results,omitempty" yaml:"internal_operation_results,omitempty"`
+}
+
+// OperationResult is implemented by results of manager operations
+type OperationResult interface {
+	OperationResultStatus() string
+	OperationResultErrors() Errors
+}
+
+// GenericOperationResult is a result of an operation of unknown kind
+type GenericOperationResult struct {
+	Status string `json:"status" yaml:"status"`
+	Errors Errors `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// OperationResultStatus implements OperationResult
+func (r *GenericOperationResult) OperationResultStatus() string {
+	return r.Status
+}
+
+// OperationResultErrors implements OperationResult
+func (r *GenericOperationResult) OperationResultErrors() Errors {
+	return r.Errors
+}
+
+// InternalOperationResult represents an operation emitted by a smart contract during the execution of its parent operation
+type InternalOperationResult struct {
+	Kind        string                 `json:"kind" yaml:"kind"`
+	Source      string                 `json:"source" yaml:"source"`
+	Nonce       int                    `json:"nonce" yaml:"nonce"`
+	Amount      *BigInt                `json:"amount,omitempty" yaml:"amount,omitempty"`           // transaction
+	Destination string                 `json:"destination,omitempty" yaml:"destination,omitempty"` // transaction
+	Parameters  map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`   // transaction
+	Balance     *BigInt                `json:"balance,omitempty" yaml:"balance,omitempty"`         // origination
+	Script      *ScriptedContracts     `json:"script,omitempty" yaml:"script,omitempty"`           // origination
+	Delegate    string                 `json:"delegate,omitempty" yaml:"delegate,omitempty"`       // origination, delegation
+	PublicKey   string                 `json:"public_key,omitempty" yaml:"public_key,omitempty"`   // reveal
+	Result      OperationResult        `json:"result" yaml:"result"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *InternalOperationResult) UnmarshalJSON(data []byte) error {
+	type suppressJSONUnmarshaller InternalOperationResult
+	var tmp struct {
+		suppressJSONUnmarshaller
+		Result json.RawMessage `json:"result"`
+	}
+
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	*r = InternalOperationResult(tmp.suppressJSONUnmarshaller)
+
+	switch r.Kind {
+	case "transaction":
+		r.Result = &TransactionOperationResult{}
+	case "origination":
+		r.Result = &OriginationOperationResult{}
+	case "delegation", "reveal":
+		r.Result = &DelegationOperationResult{}
+	default:
+		r.Result = &GenericOperationResult{}
+	}
+
+	if len(tmp.Result) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(tmp.Result, r.Result)
 }
 
 // TransactionOperationResult represents a transaction operation result
 type TransactionOperationResult struct {
 	Status              string                 `json:"status" yaml:"status"`
 	Storage             map[string]interface{} `json:"storage,omitempty" yaml:"storage,omitempty"`
+	BigMapDiff          BigMapDiff             `json:"big_map_diff,omitempty" yaml:"big_map_diff,omitempty"`
 	BalanceUpdates      BalanceUpdates         `json:"balance_updates,omitempty" yaml:"balance_updates,omitempty"`
 	OriginatedContracts []string               `json:"originated_contracts,omitempty" yaml:"originated_contracts,omitempty"`
 	ConsumedGas         *BigInt                `json:"consumed_gas,omitempty" yaml:"consumed_gas,omitempty"`
 	StorageSize         *BigInt                `json:"storage_size,omitempty" yaml:"storage_size,omitempty"`
 	PaidStorageSizeDiff *BigInt                `json:"paid_storage_size_diff,omitempty" yaml:"paid_storage_size_diff,omitempty"`
-	Errors              Errors                 `json:"errors,omitempty" yaml:"errors,omitempty"`
+	// AllocatedDestinationContract is set if the transaction has created a new implicit account
+	AllocatedDestinationContract bool   `json:"allocated_destination_contract,omitempty" yaml:"allocated_destination_contract,omitempty"`
+	Errors                       Errors `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// OperationResultStatus implements OperationResult
+func (r *TransactionOperationResult) OperationResultStatus() string {
+	return r.Status
+}
+
+// OperationResultErrors implements OperationResult
+func (r *TransactionOperationResult) OperationResultErrors() Errors {
+	return r.Errors
+}
+
+// BigMapDiffItem is a variable structure depending on the Action field
+type BigMapDiffItem interface {
+	BigMapDiffAction() string
+}
+
+// GenericBigMapDiffItem holds the common values among all BigMapDiffItem variants
+type GenericBigMapDiffItem struct {
+	Action string `json:"action" yaml:"action"`
+}
+
+// BigMapDiffAction returns the item's Action field
+func (g *GenericBigMapDiffItem) BigMapDiffAction() string {
+	return g.Action
+}
+
+// BigMapDiffUpdate is a BigMapDiffItem variant for Action=update. Value is nil if the key is removed.
+type BigMapDiffUpdate struct {
+	GenericBigMapDiffItem `yaml:",inline"`
+	BigMap                *BigInt     `json:"big_map" yaml:"big_map"`
+	KeyHash               string      `json:"key_hash" yaml:"key_hash"`
+	Key                   interface{} `json:"key" yaml:"key"`
+	Value                 interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// BigMapDiffRemove is a BigMapDiffItem variant for Action=remove
+type BigMapDiffRemove struct {
+	GenericBigMapDiffItem `yaml:",inline"`
+	BigMap                *BigInt `json:"big_map" yaml:"big_map"`
+}
+
+// BigMapDiffCopy is a BigMapDiffItem variant for Action=copy
+type BigMapDiffCopy struct {
+	GenericBigMapDiffItem `yaml:",inline"`
+	SourceBigMap          *BigInt `json:"source_big_map" yaml:"source_big_map"`
+	DestinationBigMap     *BigInt `json:"destination_big_map" yaml:"destination_big_map"`
+}
+
+// BigMapDiffAlloc is a BigMapDiffItem variant for Action=alloc
+type BigMapDiffAlloc struct {
+	GenericBigMapDiffItem `yaml:",inline"`
+	BigMap                *BigInt     `json:"big_map" yaml:"big_map"`
+	KeyType               interface{} `json:"key_type" yaml:"key_type"`
+	ValueType             interface{} `json:"value_type" yaml:"value_type"`
+}
+
+// BigMapDiff is a list of big map changes
+type BigMapDiff []BigMapDiffItem
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *BigMapDiff) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*b = make(BigMapDiff, len(raw))
+
+opLoop:
+	for i, r := range raw {
+		var tmp GenericBigMapDiffItem
+		if err := json.Unmarshal(r, &tmp); err != nil {
+			return err
+		}
+
+		switch tmp.Action {
+		case "update":
+			(*b)[i] = &BigMapDiffUpdate{}
+		case "remove":
+			(*b)[i] = &BigMapDiffRemove{}
+		case "copy":
+			(*b)[i] = &BigMapDiffCopy{}
+		case "alloc":
+			(*b)[i] = &BigMapDiffAlloc{}
+
+		default:
+			(*b)[i] = &tmp
+			continue opLoop
+		}
+
+		if err := json.Unmarshal(r, (*b)[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // BallotOperationElem represents a ballot operation
@@ -160,6 +527,11 @@ type BallotOperationElem struct {
 	Metadata             map[string]interface{} `json:"metadata" yaml:"metadata"`
 }
 
+// Vote returns the ballot value
+func (el *BallotOperationElem) Vote() BallotVote {
+	return BallotVote(el.Ballot)
+}
+
 // ProposalOperationElem represents a proposal operation
 type ProposalOperationElem struct {
 	GenericOperationElem `yaml:",inline"`
@@ -196,7 +568,7 @@ type InlinedEndorsement struct {
 
 // InlinedEndorsementContents corresponds to $inlined.endorsement.contents
 type InlinedEndorsementContents struct {
-	Kind  string `json:"endorsement" yaml:"endorsement"`
+	Kind  string `json:"kind" yaml:"kind"`
 	Level int    `json:"level" yaml:"level"`
 }
 
@@ -213,6 +585,40 @@ func (el *DoubleEndorsementEvidenceOperationElem) BalanceUpdates() BalanceUpdate
 	return el.Metadata.BalanceUpdates
 }
 
+// DoubleEndorsementMismatch holds the details of two endorsements of the double endorsement evidence
+type DoubleEndorsementMismatch struct {
+	Level1  int
+	Level2  int
+	Branch1 string
+	Branch2 string
+}
+
+// IsAccusable returns true if both endorsements are of the same level but endorse different blocks
+func (m *DoubleEndorsementMismatch) IsAccusable() bool {
+	return m.Level1 == m.Level2 && m.Branch1 != m.Branch2
+}
+
+// Mismatch returns the details of two inlined endorsements
+func (el *DoubleEndorsementEvidenceOperationElem) Mismatch() *DoubleEndorsementMismatch {
+	return &DoubleEndorsementMismatch{
+		Level1:  el.Operation1.Operations.Level,
+		Level2:  el.Operation2.Operations.Level,
+		Branch1: el.Operation1.Branch,
+		Branch2: el.Operation2.Branch,
+	}
+}
+
+// Offender returns the delegate whose deposits were slashed by the evidence. The signatures aren't verified,
+// the delegate is taken from the balance updates.
+func (el *DoubleEndorsementEvidenceOperationElem) Offender() string {
+	for _, u := range el.Metadata.BalanceUpdates {
+		if f, ok := u.(*FreezerBalanceUpdate); ok && f.Change < 0 {
+			return f.Delegate
+		}
+	}
+	return ""
+}
+
 // DoubleBakingEvidenceOperationElem represents double_baking_evidence operation
 type DoubleBakingEvidenceOperationElem struct {
 	GenericOperationElem `yaml:",inline"`
@@ -292,6 +698,11 @@ func (el *OriginationOperationElem) OperationFee() *big.Int {
 	return big.NewInt(0)
 }
 
+// BalanceMutez returns the initial balance of the originated contract as Mutez
+func (el *OriginationOperationElem) BalanceMutez() (Mutez, error) {
+	return el.Balance.Mutez()
+}
+
 // BalanceUpdates implements BalanceUpdateOperation
 func (el *OriginationOperationElem) BalanceUpdates() BalanceUpdates {
 	return el.Metadata.BalanceUpdates
@@ -299,8 +710,8 @@ func (el *OriginationOperationElem) BalanceUpdates() BalanceUpdates {
 
 // ScriptedContracts corresponds to $scripted.contracts
 type ScriptedContracts struct {
-	Code    map[string]interface{} `json:"code" yaml:"code"`
-	Storage map[string]interface{} `json:"storage" yaml:"storage"`
+	Code    *Micheline `json:"code" yaml:"code"`
+	Storage *Micheline `json:"storage" yaml:"storage"`
 }
 
 // OriginationOperationMetadata represents a origination operation metadata
@@ -312,6 +723,7 @@ type OriginationOperationMetadata struct {
 // OriginationOperationResult represents a origination operation result
 type OriginationOperationResult struct {
 	Status              string         `json:"status" yaml:"status"`
+	BigMapDiff          BigMapDiff     `json:"big_map_diff,omitempty" yaml:"big_map_diff,omitempty"`
 	BalanceUpdates      BalanceUpdates `json:"balance_updates,omitempty" yaml:"balance_updates,omitempty"`
 	OriginatedContracts []string       `json:"originated_contracts,omitempty" yaml:"originated_contracts,omitempty"`
 	ConsumedGas         *BigInt        `json:"consumed_gas,omitempty" yaml:"consumed_gas,omitempty"`
@@ -320,6 +732,16 @@ type OriginationOperationResult struct {
 	Errors              Errors         `json:"errors,omitempty" yaml:"errors,omitempty"`
 }
 
+// OperationResultStatus implements OperationResult
+func (r *OriginationOperationResult) OperationResultStatus() string {
+	return r.Status
+}
+
+// OperationResultErrors implements OperationResult
+func (r *OriginationOperationResult) OperationResultErrors() Errors {
+	return r.Errors
+}
+
 // DelegationOperationElem represents a delegation operation
 type DelegationOperationElem struct {
 	GenericOperationElem `yaml:",inline"`
@@ -358,19 +780,190 @@ type DelegationOperationMetadata struct {
 
 // DelegationOperationResult represents a delegation operation result
 type DelegationOperationResult struct {
-	Status string `json:"status" yaml:"status"`
-	Errors Errors `json:"errors" yaml:"errors"`
+	Status      string  `json:"status" yaml:"status"`
+	ConsumedGas *BigInt `json:"consumed_gas,omitempty" yaml:"consumed_gas,omitempty"`
+	Errors      Errors  `json:"errors" yaml:"errors"`
+}
+
+// OperationResultStatus implements OperationResult
+func (r *DelegationOperationResult) OperationResultStatus() string {
+	return r.Status
+}
+
+// OperationResultErrors implements OperationResult
+func (r *DelegationOperationResult) OperationResultErrors() Errors {
+	return r.Errors
+}
+
+// DALPublishSlotHeaderOperationElem represents a dal_publish_slot_header operation
+type DALPublishSlotHeaderOperationElem struct {
+	GenericOperationElem `yaml:",inline"`
+	Source               string                                `json:"source" yaml:"source"`
+	Fee                  *BigInt                               `json:"fee" yaml:"fee"`
+	Counter              *BigInt                               `json:"counter" yaml:"counter"`
+	GasLimit             *BigInt                               `json:"gas_limit" yaml:"gas_limit"`
+	StorageLimit         *BigInt                               `json:"storage_limit" yaml:"storage_limit"`
+	SlotHeader           DALSlotHeader                         `json:"slot_header" yaml:"slot_header"`
+	Metadata             DALPublishSlotHeaderOperationMetadata `json:"metadata" yaml:"metadata"`
+}
+
+// OperationFee implements OperationWithFee
+func (el *DALPublishSlotHeaderOperationElem) OperationFee() *big.Int {
+	if el.Fee != nil {
+		return &el.Fee.Int
+	}
+	return big.NewInt(0)
+}
+
+// BalanceUpdates implements BalanceUpdateOperation
+func (el *DALPublishSlotHeaderOperationElem) BalanceUpdates() BalanceUpdates {
+	return el.Metadata.BalanceUpdates
+}
+
+// DALSlotHeader represents a DAL slot header
+type DALSlotHeader struct {
+	PublishedLevel  int    `json:"published_level,omitempty" yaml:"published_level,omitempty"`
+	SlotIndex       int    `json:"slot_index" yaml:"slot_index"`
+	Commitment      string `json:"commitment" yaml:"commitment"`
+	CommitmentProof string `json:"commitment_proof,omitempty" yaml:"commitment_proof,omitempty"`
+}
+
+// DALPublishSlotHeaderOperationMetadata represents a dal_publish_slot_header operation metadata
+type DALPublishSlotHeaderOperationMetadata struct {
+	BalanceUpdates  BalanceUpdates                      `json:"balance_updates" yaml:"balance_updates"`
+	OperationResult DALPublishSlotHeaderOperationResult `json:"operation_result" yaml:"operation_result"`
+}
+
+// DALPublishSlotHeaderOperationResult represents a dal_publish_slot_header operation result
+type DALPublishSlotHeaderOperationResult struct {
+	Status           string  `json:"status" yaml:"status"`
+	ConsumedMilligas *BigInt `json:"consumed_milligas,omitempty" yaml:"consumed_milligas,omitempty"`
+	Errors           Errors  `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// OperationResultStatus implements OperationResult
+func (r *DALPublishSlotHeaderOperationResult) OperationResultStatus() string {
+	return r.Status
+}
+
+// OperationResultErrors implements OperationResult
+func (r *DALPublishSlotHeaderOperationResult) OperationResultErrors() Errors {
+	return r.Errors
+}
+
+// RegisterGlobalConstantOperationElem represents a register_global_constant operation
+type RegisterGlobalConstantOperationElem struct {
+	GenericOperationElem `yaml:",inline"`
+	Source               string                                  `json:"source" yaml:"source"`
+	Fee                  *BigInt                                 `json:"fee" yaml:"fee"`
+	Counter              *BigInt                                 `json:"counter" yaml:"counter"`
+	GasLimit             *BigInt                                 `json:"gas_limit" yaml:"gas_limit"`
+	StorageLimit         *BigInt                                 `json:"storage_limit" yaml:"storage_limit"`
+	Value                *Micheline                              `json:"value" yaml:"value"`
+	Metadata             RegisterGlobalConstantOperationMetadata `json:"metadata" yaml:"metadata"`
+}
+
+// OperationFee implements OperationWithFee
+func (el *RegisterGlobalConstantOperationElem) OperationFee() *big.Int {
+	if el.Fee != nil {
+		return &el.Fee.Int
+	}
+	return big.NewInt(0)
+}
+
+// BalanceUpdates implements BalanceUpdateOperation
+func (el *RegisterGlobalConstantOperationElem) BalanceUpdates() BalanceUpdates {
+	return el.Metadata.BalanceUpdates
+}
+
+// RegisterGlobalConstantOperationMetadata represents a register_global_constant operation metadata
+type RegisterGlobalConstantOperationMetadata struct {
+	BalanceUpdates  BalanceUpdates                        `json:"balance_updates" yaml:"balance_updates"`
+	OperationResult RegisterGlobalConstantOperationResult `json:"operation_result" yaml:"operation_result"`
+}
+
+// RegisterGlobalConstantOperationResult represents a register_global_constant operation result
+type RegisterGlobalConstantOperationResult struct {
+	Status           string         `json:"status" yaml:"status"`
+	BalanceUpdates   BalanceUpdates `json:"balance_updates,omitempty" yaml:"balance_updates,omitempty"`
+	ConsumedGas      *BigInt        `json:"consumed_gas,omitempty" yaml:"consumed_gas,omitempty"`
+	ConsumedMilligas *BigInt        `json:"consumed_milligas,omitempty" yaml:"consumed_milligas,omitempty"`
+	StorageSize      *BigInt        `json:"storage_size,omitempty" yaml:"storage_size,omitempty"`
+	GlobalAddress    string         `json:"global_address,omitempty" yaml:"global_address,omitempty"`
+	Errors           Errors         `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// OperationResultStatus implements OperationResult
+func (r *RegisterGlobalConstantOperationResult) OperationResultStatus() string {
+	return r.Status
+}
+
+// OperationResultErrors implements OperationResult
+func (r *RegisterGlobalConstantOperationResult) OperationResultErrors() Errors {
+	return r.Errors
+}
+
+// SetDepositsLimitOperationElem represents a set_deposits_limit operation. Nil Limit removes the limit
+type SetDepositsLimitOperationElem struct {
+	GenericOperationElem `yaml:",inline"`
+	Source               string                            `json:"source" yaml:"source"`
+	Fee                  *BigInt                           `json:"fee" yaml:"fee"`
+	Counter              *BigInt                           `json:"counter" yaml:"counter"`
+	GasLimit             *BigInt                           `json:"gas_limit" yaml:"gas_limit"`
+	StorageLimit         *BigInt                           `json:"storage_limit" yaml:"storage_limit"`
+	Limit                *BigInt                           `json:"limit,omitempty" yaml:"limit,omitempty"`
+	Metadata             SetDepositsLimitOperationMetadata `json:"metadata" yaml:"metadata"`
+}
+
+// OperationFee implements OperationWithFee
+func (el *SetDepositsLimitOperationElem) OperationFee() *big.Int {
+	if el.Fee != nil {
+		return &el.Fee.Int
+	}
+	return big.NewInt(0)
+}
+
+// BalanceUpdates implements BalanceUpdateOperation
+func (el *SetDepositsLimitOperationElem) BalanceUpdates() BalanceUpdates {
+	return el.Metadata.BalanceUpdates
+}
+
+// SetDepositsLimitOperationMetadata represents a set_deposits_limit operation metadata
+type SetDepositsLimitOperationMetadata struct {
+	BalanceUpdates  BalanceUpdates                  `json:"balance_updates" yaml:"balance_updates"`
+	OperationResult SetDepositsLimitOperationResult `json:"operation_result" yaml:"operation_result"`
+}
+
+// SetDepositsLimitOperationResult represents a set_deposits_limit operation result
+type SetDepositsLimitOperationResult struct {
+	Status           string  `json:"status" yaml:"status"`
+	ConsumedGas      *BigInt `json:"consumed_gas,omitempty" yaml:"consumed_gas,omitempty"`
+	ConsumedMilligas *BigInt `json:"consumed_milligas,omitempty" yaml:"consumed_milligas,omitempty"`
+	Errors           Errors  `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// OperationResultStatus implements OperationResult
+func (r *SetDepositsLimitOperationResult) OperationResultStatus() string {
+	return r.Status
+}
+
+// OperationResultErrors implements OperationResult
+func (r *SetDepositsLimitOperationResult) OperationResultErrors() Errors {
+	return r.Errors
 }
 
 // BalanceUpdate is a variable structure depending on the Kind field
 type BalanceUpdate interface {
 	BalanceUpdateKind() string
+	BalanceUpdateChange() int64
+	BalanceUpdateOrigin() string
 }
 
 // GenericBalanceUpdate holds the common values among all BalanceUpdatesType variants
 type GenericBalanceUpdate struct {
 	Kind   string `json:"kind" yaml:"kind"`
 	Change int64  `json:"change,string" yaml:"change"`
+	Origin string `json:"origin,omitempty" yaml:"origin,omitempty"`
 }
 
 // BalanceUpdateKind returns the BalanceUpdateType's Kind field
@@ -378,6 +971,16 @@ func (g *GenericBalanceUpdate) BalanceUpdateKind() string {
 	return g.Kind
 }
 
+// BalanceUpdateChange returns the BalanceUpdateType's Change field
+func (g *GenericBalanceUpdate) BalanceUpdateChange() int64 {
+	return g.Change
+}
+
+// BalanceUpdateOrigin returns the BalanceUpdateType's Origin field
+func (g *GenericBalanceUpdate) BalanceUpdateOrigin() string {
+	return g.Origin
+}
+
 // ContractBalanceUpdate is a BalanceUpdatesType variant for Kind=contract
 type ContractBalanceUpdate struct {
 	GenericBalanceUpdate `yaml:",inline"`
@@ -432,6 +1035,38 @@ opLoop:
 	return nil
 }
 
+// TotalForContract returns the sum of contract balance changes of the given contract
+func (b BalanceUpdates) TotalForContract(contract string) int64 {
+	var sum int64
+	for _, u := range b {
+		if u, ok := u.(*ContractBalanceUpdate); ok && u.Contract == contract {
+			sum += u.Change
+		}
+	}
+	return sum
+}
+
+// FreezerTotal returns the sum of frozen balance changes of the delegate in the category (deposits, fees or rewards).
+// Empty category matches any
+func (b BalanceUpdates) FreezerTotal(delegate, category string) int64 {
+	var sum int64
+	for _, u := range b {
+		if u, ok := u.(*FreezerBalanceUpdate); ok && u.Delegate == delegate && (category == "" || u.Category == category) {
+			sum += u.Change
+		}
+	}
+	return sum
+}
+
+// NetChange returns the sum of all changes. It's zero for a balanced list e.g. of a single operation
+func (b BalanceUpdates) NetChange() int64 {
+	var sum int64
+	for _, u := range b {
+		sum += u.BalanceUpdateChange()
+	}
+	return sum
+}
+
 // Operation represents an operation included into block
 type Operation struct {
 	Protocol  string            `json:"protocol" yaml:"protocol"`
@@ -442,6 +1077,196 @@ type Operation struct {
 	Signature string            `json:"signature" yaml:"signature"`
 }
 
+// balanceUpdates returns balance updates of all content elements including results of applied operations.
+// Updates of failed, skipped and backtracked results are left out as they didn't take effect
+func (o *Operation) balanceUpdates() BalanceUpdates {
+	var res BalanceUpdates
+	for _, el := range o.Contents {
+		if el, ok := el.(BalanceUpdatesOperation); ok {
+			res = append(res, el.BalanceUpdates()...)
+		}
+
+		switch el := el.(type) {
+		case *TransactionOperationElem:
+			if el.Metadata.OperationResult.Status != "applied" {
+				continue
+			}
+			res = append(res, el.Metadata.OperationResult.BalanceUpdates...)
+			for _, r := range el.Metadata.InternalOperationResults {
+				if r.Result == nil || r.Result.OperationResultStatus() != "applied" {
+					continue
+				}
+				switch r := r.Result.(type) {
+				case *TransactionOperationResult:
+					res = append(res, r.BalanceUpdates...)
+				case *OriginationOperationResult:
+					res = append(res, r.BalanceUpdates...)
+				}
+			}
+		case *OriginationOperationElem:
+			if el.Metadata.OperationResult.Status == "applied" {
+				res = append(res, el.Metadata.OperationResult.BalanceUpdates...)
+			}
+		case *RegisterGlobalConstantOperationElem:
+			if el.Metadata.OperationResult.Status == "applied" {
+				res = append(res, el.Metadata.OperationResult.BalanceUpdates...)
+			}
+		}
+	}
+	return res
+}
+
+// NetBalanceChange returns the total balance change of the contract or delegate across all content elements
+func (o *Operation) NetBalanceChange(account string) int64 {
+	var sum int64
+	for _, u := range o.balanceUpdates() {
+		var a string
+		switch u := u.(type) {
+		case *ContractBalanceUpdate:
+			a = u.Contract
+		case *FreezerBalanceUpdate:
+			a = u.Delegate
+		}
+		if a != "" && a == account {
+			sum += u.BalanceUpdateChange()
+		}
+	}
+	return sum
+}
+
+// OriginatedContracts returns addresses of all contracts originated by the operation including internal operations
+func (o *Operation) OriginatedContracts() []string {
+	var res []string
+	for _, el := range o.Contents {
+		switch el := el.(type) {
+		case *OriginationOperationElem:
+			res = append(res, el.Metadata.OperationResult.OriginatedContracts...)
+		case *TransactionOperationElem:
+			res = append(res, el.Metadata.OperationResult.OriginatedContracts...)
+			for _, r := range el.Metadata.InternalOperationResults {
+				switch r := r.Result.(type) {
+				case *TransactionOperationResult:
+					res = append(res, r.OriginatedContracts...)
+				case *OriginationOperationResult:
+					res = append(res, r.OriginatedContracts...)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// operationResults returns results of all manager content elements including internal operations
+func (o *Operation) operationResults() []OperationResult {
+	var res []OperationResult
+	for _, el := range o.Contents {
+		switch el := el.(type) {
+		case *TransactionOperationElem:
+			res = append(res, &el.Metadata.OperationResult)
+			for _, r := range el.Metadata.InternalOperationResults {
+				if r.Result != nil {
+					res = append(res, r.Result)
+				}
+			}
+		case *OriginationOperationElem:
+			res = append(res, &el.Metadata.OperationResult)
+		case *DelegationOperationElem:
+			res = append(res, &el.Metadata.OperationResult)
+		case *RevealOperationElem:
+			res = append(res, &el.Metadata.OperationResult)
+		case *DALPublishSlotHeaderOperationElem:
+			res = append(res, &el.Metadata.OperationResult)
+		case *RegisterGlobalConstantOperationElem:
+			res = append(res, &el.Metadata.OperationResult)
+		case *SetDepositsLimitOperationElem:
+			res = append(res, &el.Metadata.OperationResult)
+		}
+	}
+	return res
+}
+
+// ContainsKind returns true if the operation has at least one content element of the kind
+func (o *Operation) ContainsKind(kind string) bool {
+	for _, el := range o.Contents {
+		if el.OperationElemKind() == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ElementsOfKind returns content elements of the kind in order of appearance
+func (o *Operation) ElementsOfKind(kind string) []OperationElem {
+	var res []OperationElem
+	for _, el := range o.Contents {
+		if el.OperationElemKind() == kind {
+			res = append(res, el)
+		}
+	}
+	return res
+}
+
+// Transactions returns transaction content elements in order of appearance
+func (o *Operation) Transactions() []*TransactionOperationElem {
+	var res []*TransactionOperationElem
+	for _, el := range o.Contents {
+		if el, ok := el.(*TransactionOperationElem); ok {
+			res = append(res, el)
+		}
+	}
+	return res
+}
+
+// Sources returns distinct source accounts of all manager content elements and internal operations in order of appearance
+func (o *Operation) Sources() []string {
+	var res []string
+	seen := make(map[string]struct{})
+	add := func(src string) {
+		if _, ok := seen[src]; src != "" && !ok {
+			seen[src] = struct{}{}
+			res = append(res, src)
+		}
+	}
+
+	for _, el := range o.Contents {
+		switch el := el.(type) {
+		case *TransactionOperationElem:
+			add(el.Source)
+			for _, r := range el.Metadata.InternalOperationResults {
+				add(r.Source)
+			}
+		case *RevealOperationElem:
+			add(el.Source)
+		case *OriginationOperationElem:
+			add(el.Source)
+		case *DelegationOperationElem:
+			add(el.Source)
+		case *DALPublishSlotHeaderOperationElem:
+			add(el.Source)
+		case *RegisterGlobalConstantOperationElem:
+			add(el.Source)
+		case *SetDepositsLimitOperationElem:
+			add(el.Source)
+		}
+	}
+	return res
+}
+
+// AccusableDelegate returns the delegate accused by the double endorsement evidence contained in the operation.
+// False is returned if there is no evidence, the endorsements aren't conflicting or the offender is unknown.
+func (o *Operation) AccusableDelegate() (string, bool) {
+	for _, el := range o.Contents {
+		if ev, ok := el.(*DoubleEndorsementEvidenceOperationElem); ok {
+			if !ev.Mismatch().IsAccusable() {
+				return "", false
+			}
+			offender := ev.Offender()
+			return offender, offender != ""
+		}
+	}
+	return "", false
+}
+
 /*
 OperationAlt is a heterogeneously encoded Operation with hash as a first array member, i.e.
 	[
@@ -482,6 +1307,7 @@ func (o *OperationWithErrorAlt) UnmarshalJSON(data []byte) error {
 
 var (
 	_ BalanceUpdatesOperation = &EndorsementOperationElem{}
+	_ BalanceUpdatesOperation = &EndorsementWithSlotOperationElem{}
 	_ BalanceUpdatesOperation = &TransactionOperationElem{}
 	_ BalanceUpdatesOperation = &SeedNonceRevelationOperationElem{}
 	_ BalanceUpdatesOperation = &DoubleEndorsementEvidenceOperationElem{}
@@ -490,9 +1316,23 @@ var (
 	_ BalanceUpdatesOperation = &RevealOperationElem{}
 	_ BalanceUpdatesOperation = &OriginationOperationElem{}
 	_ BalanceUpdatesOperation = &DelegationOperationElem{}
+	_ BalanceUpdatesOperation = &DALPublishSlotHeaderOperationElem{}
+	_ BalanceUpdatesOperation = &RegisterGlobalConstantOperationElem{}
+	_ BalanceUpdatesOperation = &SetDepositsLimitOperationElem{}
 
 	_ OperationWithFee = &TransactionOperationElem{}
 	_ OperationWithFee = &RevealOperationElem{}
 	_ OperationWithFee = &OriginationOperationElem{}
 	_ OperationWithFee = &DelegationOperationElem{}
+	_ OperationWithFee = &DALPublishSlotHeaderOperationElem{}
+	_ OperationWithFee = &RegisterGlobalConstantOperationElem{}
+	_ OperationWithFee = &SetDepositsLimitOperationElem{}
+
+	_ OperationResult = &GenericOperationResult{}
+	_ OperationResult = &TransactionOperationResult{}
+	_ OperationResult = &OriginationOperationResult{}
+	_ OperationResult = &DelegationOperationResult{}
+	_ OperationResult = &DALPublishSlotHeaderOperationResult{}
+	_ OperationResult = &RegisterGlobalConstantOperationResult{}
+	_ OperationResult = &SetDepositsLimitOperationResult{}
 )
@@ -0,0 +1,677 @@
+package tezos
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bigIntMust(s string) *BigInt {
+	var v BigInt
+	if _, ok := v.SetString(s, 10); !ok {
+		panic(s)
+	}
+	return &v
+}
+
+func TestEffectiveTransfers(t *testing.T) {
+	const contents = `[
+		{
+			"kind": "transaction",
+			"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			"fee": "1420",
+			"counter": "10",
+			"gas_limit": "15385",
+			"storage_limit": "0",
+			"amount": "1000000",
+			"destination": "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv",
+			"metadata": {
+				"balance_updates": [],
+				"operation_result": {"status": "applied"},
+				"internal_operation_results": [
+					{
+						"kind": "transaction",
+						"source": "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv",
+						"nonce": 0,
+						"amount": "500000",
+						"destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+						"result": {"status": "applied"}
+					},
+					{
+						"kind": "transaction",
+						"source": "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv",
+						"nonce": 1,
+						"amount": "250000",
+						"destination": "tz1faswCTDciRzE4oJ9jn2Vm2dvjeyA9fUzU",
+						"result": {"status": "backtracked"}
+					}
+				]
+			}
+		},
+		{
+			"kind": "transaction",
+			"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			"fee": "1420",
+			"counter": "11",
+			"gas_limit": "15385",
+			"storage_limit": "0",
+			"amount": "1000000",
+			"destination": "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv",
+			"metadata": {
+				"balance_updates": [],
+				"operation_result": {"status": "backtracked"},
+				"internal_operation_results": [
+					{
+						"kind": "transaction",
+						"source": "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv",
+						"nonce": 2,
+						"amount": "500000",
+						"destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+						"result": {"status": "backtracked"}
+					}
+				]
+			}
+		}
+	]`
+
+	var elems OperationElements
+	require.NoError(t, json.Unmarshal([]byte(contents), &elems))
+	require.Len(t, elems, 2)
+
+	applied := elems[0].(*TransactionOperationElem)
+	require.Len(t, applied.Metadata.InternalOperationResults, 2)
+	require.Equal(t, []*Transfer{
+		&Transfer{Source: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", Destination: "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv", Amount: bigIntMust("1000000")},
+		&Transfer{Source: "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv", Destination: "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", Amount: bigIntMust("500000")},
+	}, applied.EffectiveTransfers())
+
+	backtracked := elems[1].(*TransactionOperationElem)
+	require.Empty(t, backtracked.EffectiveTransfers())
+}
+
+func TestOperationElementsMarshalJSON(t *testing.T) {
+	const contents = `[
+		{
+			"kind": "endorsement",
+			"level": 219132,
+			"metadata": {
+				"balance_updates": [
+					{"kind": "contract", "contract": "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", "change": "-128000000"},
+					{"kind": "freezer", "category": "deposits", "delegate": "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", "level": 106, "change": "128000000"}
+				],
+				"delegate": "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq",
+				"slots": [18, 16]
+			}
+		},
+		{
+			"kind": "transaction",
+			"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			"fee": "1420",
+			"counter": "10",
+			"gas_limit": "10307",
+			"storage_limit": "0",
+			"amount": "1000000",
+			"destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+			"metadata": {
+				"balance_updates": [
+					{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-1420"}
+				],
+				"operation_result": {
+					"status": "applied",
+					"balance_updates": [
+						{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-1000000"},
+						{"kind": "contract", "contract": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "change": "1000000"}
+					],
+					"consumed_gas": "10207"
+				}
+			}
+		}
+	]`
+
+	var elems OperationElements
+	require.NoError(t, json.Unmarshal([]byte(contents), &elems))
+
+	buf, err := json.Marshal(elems)
+	require.NoError(t, err)
+	require.JSONEq(t, contents, string(buf))
+
+	// Hand built elements must have the kind set
+	_, err = json.Marshal(OperationElements{&RevealOperationElem{Source: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}})
+	require.Error(t, err)
+}
+
+func TestDALOperations(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/dal.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Len(t, op.Contents, 3)
+
+	att, ok := op.Contents[0].(*EndorsementOperationElem)
+	require.True(t, ok)
+	require.Equal(t, 123456, att.Level)
+	require.Equal(t, bigIntMust("5"), att.DALAttestation)
+	require.Equal(t, []int{0, 3}, att.Metadata.Slots)
+
+	pub, ok := op.Contents[1].(*DALPublishSlotHeaderOperationElem)
+	require.True(t, ok)
+	require.Equal(t, 3, pub.SlotHeader.SlotIndex)
+	require.Equal(t, "sh1u3tr3YKy7a5nLBvvz3mhpcxxrYQdrMnT3NKbpmgMrJdYaRoSHRiZYN3XVGnUEEAZJTB1JZh", pub.SlotHeader.Commitment)
+	require.Equal(t, big.NewInt(513), pub.OperationFee())
+	require.Len(t, pub.BalanceUpdates(), 2)
+	require.Equal(t, "applied", pub.Metadata.OperationResult.Status)
+	require.Equal(t, bigIntMust("1332350"), pub.Metadata.OperationResult.ConsumedMilligas)
+
+	require.IsType(t, &RawOperationElem{}, op.Contents[2])
+	require.Equal(t, "dal_entrapment_evidence", op.Contents[2].OperationElemKind())
+}
+
+func TestNetBalanceChange(t *testing.T) {
+	const (
+		sender   = "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"
+		receiver = "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"
+		baker    = "tz1Ke2h7sDdakHJQh8WX4Z372du1KChsksyU"
+	)
+
+	op := Operation{
+		Contents: OperationElements{
+			&TransactionOperationElem{
+				GenericOperationElem: GenericOperationElem{Kind: "transaction"},
+				Source:               sender,
+				Destination:          receiver,
+				Metadata: TransactionOperationMetadata{
+					BalanceUpdates: BalanceUpdates{
+						&ContractBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: -1420}, Contract: sender},
+						&FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 1420}, Category: "fees", Delegate: baker},
+					},
+					OperationResult: TransactionOperationResult{
+						Status: "applied",
+						BalanceUpdates: BalanceUpdates{
+							&ContractBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: -1000000}, Contract: sender},
+							&ContractBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: 1000000}, Contract: receiver},
+						},
+					},
+				},
+			},
+			&SeedNonceRevelationOperationElem{
+				GenericOperationElem: GenericOperationElem{Kind: "seed_nonce_revelation"},
+				Metadata: BalanceUpdatesOperationMetadata{
+					BalanceUpdates: BalanceUpdates{
+						&FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 125000}, Category: "rewards", Delegate: baker},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, int64(-1001420), op.NetBalanceChange(sender))
+	require.Equal(t, int64(1000000), op.NetBalanceChange(receiver))
+	require.Equal(t, int64(126420), op.NetBalanceChange(baker))
+	require.Equal(t, int64(0), op.NetBalanceChange("tz1burnburnburnburnburnburnburjAYjjX"))
+}
+
+func TestCallTree(t *testing.T) {
+	const (
+		user   = "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"
+		router = "KT1Router"
+		dex    = "KT1Dex"
+		token  = "KT1Token"
+		payee  = "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"
+	)
+
+	// user -> router -> (dex -> token, payee)
+	internal := []*InternalOperationResult{
+		&InternalOperationResult{Kind: "transaction", Source: router, Destination: payee, Nonce: 1, Amount: bigIntMust("10")},
+		&InternalOperationResult{Kind: "transaction", Source: dex, Destination: token, Nonce: 2, Amount: bigIntMust("0")},
+		&InternalOperationResult{Kind: "transaction", Source: router, Destination: dex, Nonce: 0, Amount: bigIntMust("90")},
+	}
+
+	el := TransactionOperationElem{
+		Source:      user,
+		Destination: router,
+		Amount:      bigIntMust("100"),
+		Metadata: TransactionOperationMetadata{
+			InternalOperationResults: internal,
+		},
+	}
+
+	root := el.CallTree()
+	require.Equal(t, user, root.Source)
+	require.Equal(t, router, root.Destination)
+	require.Nil(t, root.Operation)
+	require.Len(t, root.Children, 2)
+
+	require.Equal(t, internal[2], root.Children[0].Operation)
+	require.Equal(t, internal[0], root.Children[1].Operation)
+	require.Empty(t, root.Children[1].Children)
+
+	require.Len(t, root.Children[0].Children, 1)
+	require.Equal(t, internal[1], root.Children[0].Children[0].Operation)
+	require.Equal(t, token, root.Children[0].Children[0].Destination)
+	require.Empty(t, root.Children[0].Children[0].Children)
+}
+
+func TestOriginatedContracts(t *testing.T) {
+	op := Operation{
+		Contents: OperationElements{
+			&RevealOperationElem{GenericOperationElem: GenericOperationElem{Kind: "reveal"}},
+			&OriginationOperationElem{
+				GenericOperationElem: GenericOperationElem{Kind: "origination"},
+				Metadata: OriginationOperationMetadata{
+					OperationResult: OriginationOperationResult{
+						Status:              "applied",
+						OriginatedContracts: []string{"KT1XBBwMkKH9ZrTaAv4BvQzxfVFaGXqHRjoe"},
+					},
+				},
+			},
+			&TransactionOperationElem{
+				GenericOperationElem: GenericOperationElem{Kind: "transaction"},
+				Metadata: TransactionOperationMetadata{
+					OperationResult: TransactionOperationResult{Status: "applied"},
+					InternalOperationResults: []*InternalOperationResult{
+						&InternalOperationResult{
+							Kind: "origination",
+							Result: &OriginationOperationResult{
+								Status:              "applied",
+								OriginatedContracts: []string{"KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, []string{"KT1XBBwMkKH9ZrTaAv4BvQzxfVFaGXqHRjoe", "KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo"}, op.OriginatedContracts())
+}
+
+func TestInternalOperationResults(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/internal_operations.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Len(t, op.Contents, 1)
+
+	tx, ok := op.Contents[0].(*TransactionOperationElem)
+	require.True(t, ok)
+	internal := tx.Metadata.InternalOperationResults
+	require.Len(t, internal, 2)
+
+	require.Equal(t, "transaction", internal[0].Kind)
+	require.Equal(t, "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", internal[0].Destination)
+	require.Equal(t, bigIntMust("1000000"), internal[0].Amount)
+	txResult, ok := internal[0].Result.(*TransactionOperationResult)
+	require.True(t, ok)
+	require.Equal(t, "applied", txResult.OperationResultStatus())
+	require.Equal(t, bigIntMust("10207"), txResult.ConsumedGas)
+
+	require.Equal(t, "origination", internal[1].Kind)
+	require.Equal(t, 1, internal[1].Nonce)
+	require.Equal(t, bigIntMust("500000"), internal[1].Balance)
+	require.NotNil(t, internal[1].Script)
+	origResult, ok := internal[1].Result.(*OriginationOperationResult)
+	require.True(t, ok)
+	require.Equal(t, []string{"KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo"}, origResult.OriginatedContracts)
+	require.Equal(t, bigIntMust("232"), origResult.PaidStorageSizeDiff)
+
+	require.Equal(t, []string{"KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo"}, op.OriginatedContracts())
+	require.Equal(t, int64(-2003000), op.NetBalanceChange("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"))
+	require.Equal(t, int64(500000), op.NetBalanceChange("KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv"))
+}
+
+func TestNetBalanceChangeBacktracked(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/backtracked.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+
+	// Only fees are paid
+	require.Equal(t, int64(-2840), op.NetBalanceChange("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"))
+	require.Equal(t, int64(2840), op.NetBalanceChange("tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"))
+	require.Equal(t, int64(0), op.NetBalanceChange("KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv"))
+	require.Equal(t, int64(0), op.NetBalanceChange("tz1faswCTDciRzE4oJ9jn2Vm2dvjeyA9fUzU"))
+	require.Len(t, op.balanceUpdates(), 4)
+	require.Equal(t, int64(-1420), op.balanceUpdates()[0].BalanceUpdateChange())
+	require.Equal(t, int64(0), op.balanceUpdates().NetChange())
+}
+
+func TestBigMapDiff(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/big_map_diff.json")
+	require.NoError(t, err)
+
+	var res TransactionOperationResult
+	require.NoError(t, json.Unmarshal(data, &res))
+	require.Equal(t, BigMapDiff{
+		&BigMapDiffAlloc{
+			GenericBigMapDiffItem: GenericBigMapDiffItem{Action: "alloc"},
+			BigMap:                bigIntMust("17"),
+			KeyType:               map[string]interface{}{"prim": "address"},
+			ValueType:             map[string]interface{}{"prim": "nat"},
+		},
+		&BigMapDiffUpdate{
+			GenericBigMapDiffItem: GenericBigMapDiffItem{Action: "update"},
+			BigMap:                bigIntMust("17"),
+			KeyHash:               "expruH3qgknRBJVLVkwdzf6wfBxd7Y1uqNxr7zuMFxTC12e5PacLfv",
+			Key:                   map[string]interface{}{"bytes": "000002298c03ed7d454a101eb7022bc95f7e5f41ac78"},
+			Value:                 map[string]interface{}{"int": "1000"},
+		},
+		&GenericBigMapDiffItem{Action: "burn"},
+	}, res.BigMapDiff)
+}
+
+func TestRawOperationElemRoundTrip(t *testing.T) {
+	const contents = `[
+		{
+			"kind": "smart_rollup_add_messages",
+			"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			"fee": "400",
+			"counter": "13",
+			"gas_limit": "1100",
+			"storage_limit": "0",
+			"message": ["0001", "0002"]
+		},
+		{
+			"kind": "transaction",
+			"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			"fee": "1420",
+			"counter": "14",
+			"gas_limit": "10307",
+			"storage_limit": "0",
+			"amount": "1000000",
+			"destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+			"metadata": {"balance_updates": [], "operation_result": {"status": "applied"}}
+		}
+	]`
+
+	var elems OperationElements
+	require.NoError(t, json.Unmarshal([]byte(contents), &elems))
+	require.IsType(t, &RawOperationElem{}, elems[0])
+
+	// The payload of the unknown kind stays accessible
+	var rollup struct {
+		Source  string   `json:"source"`
+		Message []string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(elems[0].(*RawOperationElem).Raw, &rollup))
+	require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", rollup.Source)
+	require.Equal(t, []string{"0001", "0002"}, rollup.Message)
+
+	buf, err := json.Marshal(elems)
+	require.NoError(t, err)
+	require.JSONEq(t, contents, string(buf))
+}
+
+func TestOriginationSimulation(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/origination_simulation.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Len(t, op.Contents, 1)
+
+	orig, ok := op.Contents[0].(*OriginationOperationElem)
+	require.True(t, ok)
+	require.Nil(t, orig.Script)
+	require.Equal(t, bigIntMust("0"), orig.Balance)
+	require.Equal(t, "applied", orig.Metadata.OperationResult.Status)
+	require.Equal(t, []string{"KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo"}, orig.Metadata.OperationResult.OriginatedContracts)
+	require.Equal(t, []string{"KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo"}, op.OriginatedContracts())
+
+	for _, proto := range []string{ProtocolAthens, ProtocolBabylon} {
+		ops, err := UnmarshalOperationsForProtocol([]byte("["+string(data)+"]"), &DecodeOptions{Protocol: proto})
+		require.NoError(t, err, proto)
+		orig, ok := ops[0].Contents[0].(*OriginationOperationElem)
+		require.True(t, ok, proto)
+		require.Nil(t, orig.Script, proto)
+		require.Equal(t, []string{"KT1GgUJwMQoFayRYNwamRAYCvHBLzgorLoGo"}, orig.Metadata.OperationResult.OriginatedContracts, proto)
+	}
+}
+
+func TestEndorsingPower(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected int
+	}{
+		{data: `{"delegate": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "slots": [3, 11, 27]}`, expected: 3},
+		{data: `{"delegate": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "endorsement_power": 212}`, expected: 212},
+		{data: `{"delegate": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}`, expected: 0},
+	}
+
+	for _, c := range cases {
+		var m EndorsementOperationMetadata
+		require.NoError(t, json.Unmarshal([]byte(c.data), &m))
+		require.Equal(t, c.expected, m.EndorsingPower(), c.data)
+	}
+}
+
+func TestAccusableDelegate(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/double_endorsement_evidence.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+
+	ev, ok := op.Contents[0].(*DoubleEndorsementEvidenceOperationElem)
+	require.True(t, ok)
+	require.Equal(t, &DoubleEndorsementMismatch{
+		Level1:  424575,
+		Level2:  424575,
+		Branch1: "BMSVXbZz4DEn6tBj4NTKEfbCpJGeWf4NZUvGbmj5kTjXtxvnHvy",
+		Branch2: "BLuvR5mDsYBUNcSCq6cQWP2JfNDqMTmfxHEJ3Pz7uvPB8oqRczX",
+	}, ev.Mismatch())
+	require.Equal(t, "endorsement", ev.Operation1.Operations.Kind)
+
+	delegate, ok := op.AccusableDelegate()
+	require.True(t, ok)
+	require.Equal(t, "tz1Kt4P8BCaP93AEV4eA7gmpRryWt5hznjCP", delegate)
+
+	// Same block endorsed twice isn't a double endorsement
+	ev.Operation2.Branch = ev.Operation1.Branch
+	_, ok = op.AccusableDelegate()
+	require.False(t, ok)
+
+	_, ok = (&Operation{}).AccusableDelegate()
+	require.False(t, ok)
+}
+
+func TestTransactionParameters(t *testing.T) {
+	cases := []struct {
+		data       string
+		entrypoint string
+		value      map[string]interface{}
+		ok         bool
+	}{
+		{
+			data:       `{"kind":"transaction","parameters":{"entrypoint":"transfer","value":{"prim":"Pair","args":[{"string":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"},{"int":"1"}]}}}`,
+			entrypoint: "transfer",
+			value:      map[string]interface{}{"prim": "Pair", "args": []interface{}{map[string]interface{}{"string": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}, map[string]interface{}{"int": "1"}}},
+			ok:         true,
+		},
+		{
+			data:       `{"kind":"transaction","parameters":{"prim":"Left","args":[{"prim":"Unit"}]}}`,
+			entrypoint: "default",
+			value:      map[string]interface{}{"prim": "Left", "args": []interface{}{map[string]interface{}{"prim": "Unit"}}},
+			ok:         true,
+		},
+		{
+			data: `{"kind":"transaction"}`,
+		},
+	}
+
+	for _, c := range cases {
+		var el TransactionOperationElem
+		require.NoError(t, json.Unmarshal([]byte(c.data), &el))
+
+		entrypoint, ok := el.Entrypoint()
+		require.Equal(t, c.ok, ok, c.data)
+		require.Equal(t, c.entrypoint, entrypoint, c.data)
+
+		value, ok := el.ParameterValue()
+		require.Equal(t, c.ok, ok, c.data)
+		require.Equal(t, c.value, value, c.data)
+	}
+}
+
+func TestOperationSources(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/internal_operations.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Equal(t, []string{"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv"}, op.Sources())
+
+	require.Empty(t, (&Operation{}).Sources())
+}
+
+func TestBalanceUpdatesTotals(t *testing.T) {
+	data := `[
+		{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-1420"},
+		{"kind": "freezer", "category": "fees", "delegate": "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5", "level": 100, "change": "1420"},
+		{"kind": "contract", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "change": "-1000000"},
+		{"kind": "contract", "contract": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", "change": "1000000"},
+		{"kind": "contract", "contract": "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5", "change": "-512000000"},
+		{"kind": "freezer", "category": "deposits", "delegate": "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5", "level": 100, "change": "512000000"}
+	]`
+
+	var updates BalanceUpdates
+	require.NoError(t, json.Unmarshal([]byte(data), &updates))
+
+	require.Equal(t, int64(-1001420), updates.TotalForContract("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"))
+	require.Equal(t, int64(1000000), updates.TotalForContract("tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"))
+	require.Equal(t, int64(0), updates.TotalForContract("tz1VZJXcUtRqTpU4VKBmWbXdYkGJbHDuSCmz"))
+	require.Equal(t, int64(1420), updates.FreezerTotal("tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5", "fees"))
+	require.Equal(t, int64(512000000), updates.FreezerTotal("tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5", "deposits"))
+	require.Equal(t, int64(512001420), updates.FreezerTotal("tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5", ""))
+	require.Equal(t, int64(0), updates.NetChange())
+
+	require.Equal(t, int64(-1420), updates[:1].NetChange())
+}
+
+func TestOperationKinds(t *testing.T) {
+	data := `{
+		"contents": [
+			{"kind": "reveal", "source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "fee": "1269", "counter": "1", "gas_limit": "10000", "storage_limit": "0", "public_key": "edpktsPhZ8weLEXqf4Fo5FS9Qx8ZuX4QpEBEwe63L747G8iDjTAF6w"},
+			{"kind": "transaction", "source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "fee": "1420", "counter": "2", "gas_limit": "10307", "storage_limit": "0", "amount": "1000000", "destination": "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"},
+			{"kind": "transaction", "source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "fee": "1420", "counter": "3", "gas_limit": "10307", "storage_limit": "0", "amount": "2000000", "destination": "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43"}
+		]
+	}`
+
+	var op Operation
+	require.NoError(t, json.Unmarshal([]byte(data), &op))
+
+	require.True(t, op.ContainsKind("reveal"))
+	require.True(t, op.ContainsKind("transaction"))
+	require.False(t, op.ContainsKind("endorsement"))
+
+	require.Len(t, op.ElementsOfKind("reveal"), 1)
+	require.IsType(t, &RevealOperationElem{}, op.ElementsOfKind("reveal")[0])
+	require.Equal(t, []OperationElem{op.Contents[1], op.Contents[2]}, op.ElementsOfKind("transaction"))
+	require.Empty(t, op.ElementsOfKind("endorsement"))
+
+	txs := op.Transactions()
+	require.Len(t, txs, 2)
+	require.Equal(t, "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN", txs[0].Destination)
+	require.Equal(t, "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", txs[1].Destination)
+}
+
+func TestEndorsementWithSlot(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/endorsement_with_slot.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Len(t, op.Contents, 1)
+
+	el, ok := op.Contents[0].(*EndorsementWithSlotOperationElem)
+	require.True(t, ok)
+	require.Equal(t, "endorsement_with_slot", el.OperationElemKind())
+	require.Equal(t, 12, el.Slot)
+	require.Equal(t, InlinedEndorsement{
+		Branch:     "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm",
+		Operations: InlinedEndorsementContents{Kind: "endorsement", Level: 1466368},
+		Signature:  "sigS3d9wfEFuChEqLetCxf4G8QYAjWL7ND3F8amMPVPDS2RwQqkeKU9hbrEXk7GG7U2aPcWkTA3uTdNzz4gkAb8jSy8hUc51",
+	}, el.Endorsement)
+	require.Equal(t, "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", el.Metadata.Delegate)
+	require.Equal(t, 3, el.Metadata.EndorsingPower())
+	require.Equal(t, int64(0), el.BalanceUpdates().NetChange())
+}
+
+func TestRegisterGlobalConstant(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/register_global_constant.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Len(t, op.Contents, 1)
+
+	el, ok := op.Contents[0].(*RegisterGlobalConstantOperationElem)
+	require.True(t, ok)
+	require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", el.Source)
+	require.Equal(t, big.NewInt(372), el.OperationFee())
+	require.Equal(t, bigIntMust("15"), el.Counter)
+	require.Equal(t, &Micheline{
+		Kind: MichelinePrim,
+		Prim: "Pair",
+		Args: []*Micheline{{Kind: MichelineInt, Int: big.NewInt(1)}, {Kind: MichelineString, String: "foo"}},
+	}, el.Value)
+	require.Equal(t, "applied", el.Metadata.OperationResult.Status)
+	require.Equal(t, "exprvP7wtwpVpcyNmR9Sq8zCgtuXB5AGpqYtfzp1AGNBfq4S1ycGSZ", el.Metadata.OperationResult.GlobalAddress)
+	require.Equal(t, bigIntMust("74"), el.Metadata.OperationResult.StorageSize)
+	require.Equal(t, []string{"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}, op.Sources())
+	// Fee and storage burn
+	require.Equal(t, int64(-18872), op.NetBalanceChange("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"))
+}
+
+func TestSetDepositsLimit(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/set_deposits_limit.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+	require.Len(t, op.Contents, 2)
+
+	set, ok := op.Contents[0].(*SetDepositsLimitOperationElem)
+	require.True(t, ok)
+	require.Equal(t, "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", set.Source)
+	require.Equal(t, bigIntMust("10000000000"), set.Limit)
+	require.Equal(t, big.NewInt(349), set.OperationFee())
+	require.Equal(t, int64(0), set.BalanceUpdates().NetChange())
+	require.Equal(t, "applied", set.Metadata.OperationResult.Status)
+	require.Equal(t, bigIntMust("1000"), set.Metadata.OperationResult.ConsumedGas)
+
+	clear, ok := op.Contents[1].(*SetDepositsLimitOperationElem)
+	require.True(t, ok)
+	require.Nil(t, clear.Limit)
+	require.Equal(t, "applied", clear.Metadata.OperationResult.Status)
+
+	// Clearing the limit omits it altogether
+	buf, err := json.Marshal(clear)
+	require.NoError(t, err)
+	require.NotContains(t, string(buf), `"limit"`)
+}
+
+func TestRegisterGlobalConstantSequence(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/register_global_constant_seq.json")
+	require.NoError(t, err)
+
+	var op Operation
+	require.NoError(t, json.Unmarshal(data, &op))
+
+	el, ok := op.Contents[0].(*RegisterGlobalConstantOperationElem)
+	require.True(t, ok)
+	require.Equal(t, &Micheline{
+		Kind: MichelineSeq,
+		Seq:  []*Micheline{{Kind: MichelinePrim, Prim: "DROP"}, {Kind: MichelinePrim, Prim: "UNIT"}},
+	}, el.Value)
+	require.Equal(t, "expruQN5r2umbZVHy6WynYM8f71F8zS4AERz9bugF8UkPBEqrHLuU8", el.Metadata.OperationResult.GlobalAddress)
+
+	buf, err := json.Marshal(op.Contents)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `"value":[{"prim":"DROP"},{"prim":"UNIT"}]`)
+}
@@ -0,0 +1,133 @@
+package tezos
+
+import (
+	"encoding/json"
+)
+
+// Known protocol hashes
+const (
+	ProtocolAlpha     = "PtCJ7pwoxe8JasnHY8YonnLYjcVHmhiARPJvqcC6VfHT5s8k8sY"
+	ProtocolBetanet   = "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt"
+	ProtocolMainnet   = "PsddFKi32cMJ2qPjf43Qv5GDWLDPZb3T3bF6fLKiF5HtvHNU7aP"
+	ProtocolAthens    = "Pt24m4xiPbLDhVgVfABUjirbmda3yohdN82Sp9FeuAXJ4eV9otd"
+	ProtocolBabylon   = "PsBabyM1eUXZseaJdmXFApDSBqj8YBfwELoxZHHW77EMcAbbwAS"
+	ProtocolBabylon2  = "PsBABY5HQTSkA4297zNHfsZNKtxULfL18y95qb3m53QJiXGmrbU"
+	ProtocolCarthage  = "PsCARTHAGazKbHtnKfLzQg3kms52kSRpgnDY982a9oYsSXRLQEb"
+	ProtocolCarthage2 = "PryLyZ8A11FXDr1tRE9zQ7Di6Y8zX48RfFCFpkjC8Pt9yCBLhtN"
+)
+
+type originationFormat int
+
+const (
+	// Babylon and later: no manager key, spendable and delegatable flags
+	originationFormatCurrent originationFormat = iota
+	// 001 and 002: managerPubkey
+	originationFormatCamelCase
+	// 003 and 004: manager_pubkey
+	originationFormatSnakeCase
+)
+
+var legacyOriginationFormats = map[string]originationFormat{
+	ProtocolAlpha:   originationFormatCamelCase,
+	ProtocolBetanet: originationFormatCamelCase,
+	ProtocolMainnet: originationFormatSnakeCase,
+	ProtocolAthens:  originationFormatSnakeCase,
+}
+
+// DecodeOptions controls protocol specific decoding of operations
+type DecodeOptions struct {
+	// Protocol hash. If empty the protocol of each operation is used.
+	Protocol string
+	// Micheline enables decoding of transaction parameters into Micheline AST
+	Micheline bool
+}
+
+// UnmarshalOperationsForProtocol decodes a JSON array of operations using field names specific to the protocol.
+// Unknown protocols are assumed to be Babylon or later.
+func UnmarshalOperationsForProtocol(data []byte, opts *DecodeOptions) ([]*Operation, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	ops := make([]*Operation, len(raw))
+	for i, r := range raw {
+		var op Operation
+		if err := json.Unmarshal(r, &op); err != nil {
+			return nil, err
+		}
+
+		var tmp struct {
+			Contents []json.RawMessage `json:"contents"`
+		}
+		if err := json.Unmarshal(r, &tmp); err != nil {
+			return nil, err
+		}
+
+		protocol := op.Protocol
+		if opts != nil && opts.Protocol != "" {
+			protocol = opts.Protocol
+		}
+
+		for j, el := range op.Contents {
+			if err := fixupOperationElem(el, tmp.Contents[j], protocol); err != nil {
+				return nil, err
+			}
+			if opts != nil && opts.Micheline {
+				if err := decodeOperationElemMicheline(el, tmp.Contents[j]); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		ops[i] = &op
+	}
+
+	return ops, nil
+}
+
+func fixupOperationElem(el OperationElem, data []byte, protocol string) error {
+	switch el := el.(type) {
+	case *OriginationOperationElem:
+		switch legacyOriginationFormats[protocol] {
+		case originationFormatCurrent:
+			el.ManagerPubKey = ""
+			el.Spendable = nil
+			el.Delegatable = nil
+
+		case originationFormatSnakeCase:
+			var tmp struct {
+				ManagerPubKey string `json:"manager_pubkey"`
+			}
+			if err := json.Unmarshal(data, &tmp); err != nil {
+				return err
+			}
+			el.ManagerPubKey = tmp.ManagerPubKey
+		}
+	}
+
+	return nil
+}
+
+func decodeOperationElemMicheline(el OperationElem, data []byte) (err error) {
+	switch el := el.(type) {
+	case *TransactionOperationElem:
+		var tmp struct {
+			Parameters json.RawMessage `json:"parameters"`
+		}
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			return err
+		}
+		// Babylon and later wrap the value along with the entrypoint
+		var wrapped struct {
+			Entrypoint *string         `json:"entrypoint"`
+			Value      json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(tmp.Parameters, &wrapped); err == nil && wrapped.Entrypoint != nil {
+			tmp.Parameters = wrapped.Value
+		}
+		el.ParametersMicheline, err = unmarshalMichelineField(tmp.Parameters)
+	}
+
+	return err
+}
@@ -0,0 +1,83 @@
+package tezos
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalOperationsForProtocol(t *testing.T) {
+	boolPtr := func(v bool) *bool { return &v }
+
+	t.Run("Athens", func(t *testing.T) {
+		data, err := ioutil.ReadFile("fixtures/operations/origination_athens.json")
+		require.NoError(t, err)
+
+		ops, err := UnmarshalOperationsForProtocol(data, nil)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		require.Len(t, ops[0].Contents, 1)
+
+		el, ok := ops[0].Contents[0].(*OriginationOperationElem)
+		require.True(t, ok)
+		require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", el.ManagerPubKey)
+		require.Equal(t, boolPtr(true), el.Spendable)
+		require.Equal(t, boolPtr(false), el.Delegatable)
+		require.Equal(t, bigIntMust("1000000"), el.Balance)
+	})
+
+	t.Run("Babylon", func(t *testing.T) {
+		data, err := ioutil.ReadFile("fixtures/operations/origination_babylon.json")
+		require.NoError(t, err)
+
+		ops, err := UnmarshalOperationsForProtocol(data, nil)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		require.Len(t, ops[0].Contents, 1)
+
+		el, ok := ops[0].Contents[0].(*OriginationOperationElem)
+		require.True(t, ok)
+		require.Empty(t, el.ManagerPubKey)
+		require.Nil(t, el.Spendable)
+		require.Nil(t, el.Delegatable)
+		require.NotNil(t, el.Script)
+		require.Equal(t, bigIntMust("1000000"), el.Balance)
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		// Pre-Babylon fields are ignored if the protocol is overridden
+		data, err := ioutil.ReadFile("fixtures/operations/origination_athens.json")
+		require.NoError(t, err)
+
+		ops, err := UnmarshalOperationsForProtocol(data, &DecodeOptions{Protocol: ProtocolBabylon})
+		require.NoError(t, err)
+
+		el := ops[0].Contents[0].(*OriginationOperationElem)
+		require.Empty(t, el.ManagerPubKey)
+		require.Nil(t, el.Spendable)
+		require.Nil(t, el.Delegatable)
+	})
+}
+
+func TestNumericCounter(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/operations/numeric_counter.json")
+	require.NoError(t, err)
+
+	ops, err := UnmarshalOperationsForProtocol(data, nil)
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+
+	tx, ok := ops[0].Contents[0].(*TransactionOperationElem)
+	require.True(t, ok)
+	require.Equal(t, bigIntMust("10"), tx.Counter)
+	require.Equal(t, bigIntMust("10307"), tx.GasLimit)
+	require.Equal(t, bigIntMust("0"), tx.StorageLimit)
+	require.Equal(t, bigIntMust("1420"), tx.Fee)
+}
+
+func TestProtocolHashes(t *testing.T) {
+	for _, p := range []string{ProtocolAlpha, ProtocolBetanet, ProtocolMainnet, ProtocolAthens, ProtocolBabylon, ProtocolBabylon2, ProtocolCarthage, ProtocolCarthage2} {
+		require.NoError(t, ValidateID(p, IDProtocol), p)
+	}
+}
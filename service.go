@@ -1,12 +1,17 @@
 package tezos
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -15,6 +20,21 @@ import (
 // Service implements fetching of information from Tezos nodes via JSON.
 type Service struct {
 	Client *RPCClient
+	// Concurrency, if set, adaptively limits the number of simultaneous requests made by bulk methods
+	// below their concurrency argument
+	Concurrency *AdaptiveConcurrency
+
+	constantsMtx sync.Mutex
+	constants    map[string]*protocolConstants // by chain id
+
+	opCacheOnce   sync.Once
+	opCache       *lruCache // operation hash to block hash
+	opBlocksCache *lruCache // level to hash of scanned blocks
+}
+
+type protocolConstants struct {
+	protocol  string
+	constants *Constants
 }
 
 // NetworkStats models global network bandwidth totals and usage in B/s.
@@ -90,6 +110,15 @@ type NetworkPeer struct {
 	LastMiss                  *NetworkConnectionTimestamp `json:"last_miss"`
 }
 
+// String returns a short description of the peer suitable for logging
+func (n *NetworkPeer) String() string {
+	s := n.PeerID + " (" + n.State
+	if n.ReachableAt != nil {
+		s += ", " + net.JoinHostPort(n.ReachableAt.Addr, strconv.FormatUint(uint64(n.ReachableAt.Port), 10))
+	}
+	return s + ")"
+}
+
 // networkPeerWithID is a heterogeneously encoded NetworkPeer with ID as a first array member
 // See OperationAlt for details
 type networkPeerWithID NetworkPeer
@@ -101,6 +130,7 @@ func (n *networkPeerWithID) UnmarshalJSON(data []byte) error {
 // NetworkPeerLogEntry represents peer log entry
 type NetworkPeerLogEntry struct {
 	NetworkAddress
+	PeerID    string    `json:"-"`
 	Kind      string    `json:"kind"`
 	Timestamp time.Time `json:"timestamp"`
 }
@@ -160,6 +190,39 @@ type MempoolOperations struct {
 	Unprocessed   []*OperationAlt          `json:"unprocessed"`
 }
 
+// DiffMempool compares applied operations of two mempool snapshots by their hashes
+// and returns operations added to and removed from the next snapshot
+func DiffMempool(prev, next *MempoolOperations) (added, removed []*Operation) {
+	var prevOps, nextOps []*Operation
+	if prev != nil {
+		prevOps = prev.Applied
+	}
+	if next != nil {
+		nextOps = next.Applied
+	}
+
+	prevHashes := make(map[string]struct{}, len(prevOps))
+	for _, op := range prevOps {
+		prevHashes[op.Hash] = struct{}{}
+	}
+
+	nextHashes := make(map[string]struct{}, len(nextOps))
+	for _, op := range nextOps {
+		nextHashes[op.Hash] = struct{}{}
+		if _, ok := prevHashes[op.Hash]; !ok {
+			added = append(added, op)
+		}
+	}
+
+	for _, op := range prevOps {
+		if _, ok := nextHashes[op.Hash]; !ok {
+			removed = append(removed, op)
+		}
+	}
+
+	return added, removed
+}
+
 // InvalidBlock represents invalid block hash along with the errors that led to it being declared invalid
 type InvalidBlock struct {
 	Block string `json:"block"`
@@ -167,6 +230,25 @@ type InvalidBlock struct {
 	Error Errors `json:"error"`
 }
 
+// ActiveChain is an item of the active chains list. TestProtocol and ExpirationDate are set for test chains
+// and Stopping is set for chains being stopped.
+type ActiveChain struct {
+	ChainID        string     `json:"chain_id"`
+	TestProtocol   string     `json:"test_protocol,omitempty"`
+	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+	Stopping       string     `json:"stopping,omitempty"`
+}
+
+// BlocksOptions holds optional query parameters of GetBlocks
+type BlocksOptions struct {
+	// Length is the number of predecessors listed for each head including the head itself
+	Length int
+	// Head lists fragments of the chain ending with the given blocks instead of the current heads
+	Head []string
+	// MinDate filters out heads older than the given date
+	MinDate time.Time
+}
+
 type proposalsRPCResponse = [][]interface{}
 
 // BigInt overrides UnmarshalJSON for big.Int
@@ -174,15 +256,44 @@ type BigInt struct {
 	big.Int
 }
 
-// UnmarshalJSON implements json.Unmarshaler
+// UnmarshalJSON implements json.Unmarshaler. Both quoted and bare numbers are accepted
+// as the node isn't consistent across fields and protocol versions.
 func (z *BigInt) UnmarshalJSON(data []byte) error {
-	var s string
-	// basically unquote only
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) != 0 && data[0] == '"' {
+		var s string
+		// basically unquote only
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
 	}
 
-	return z.UnmarshalText([]byte(s))
+	return z.UnmarshalText(data)
+}
+
+// MarshalJSON implements json.Marshaler. The value is always encoded as a quoted string.
+func (z *BigInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(z.String())
+}
+
+// Int64 returns the int64 representation of z and true if it fits into int64
+func (z *BigInt) Int64() (int64, bool) {
+	return z.Int.Int64(), z.IsInt64()
+}
+
+// Add sets z to the sum x+y and returns z
+func (z *BigInt) Add(x, y *BigInt) *BigInt {
+	z.Int.Add(&x.Int, &y.Int)
+	return z
+}
+
+// Cmp compares z and y and returns -1, 0 or +1
+func (z *BigInt) Cmp(y *BigInt) int {
+	return z.Int.Cmp(&y.Int)
 }
 
 // MarshalYAML implements yaml.Marshaler
@@ -221,6 +332,34 @@ func (s *Service) GetNetworkConnections(ctx context.Context) ([]*NetworkConnecti
 	return conns, err
 }
 
+// GetNetworkSelf returns the node's own peer id https://tezos.gitlab.io/mainnet/api/rpc.html#get-network-self
+func (s *Service) GetNetworkSelf(ctx context.Context) (string, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/network/self", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	if err = s.Client.Do(req, &id); err != nil {
+		return "", err
+	}
+	return id, err
+}
+
+// GetNetworkVersions returns the supported network protocol versions https://tezos.gitlab.io/mainnet/api/rpc.html#get-network-versions
+func (s *Service) GetNetworkVersions(ctx context.Context) ([]*NetworkVersion, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/network/versions", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*NetworkVersion
+	if err = s.Client.Do(req, &versions); err != nil {
+		return nil, err
+	}
+	return versions, err
+}
+
 // GetNetworkPeers returns the list the peers the node ever met.
 // https://tezos.gitlab.io/mainnet/api/rpc.html#get-network-peers
 func (s *Service) GetNetworkPeers(ctx context.Context, filter string) ([]*NetworkPeer, error) {
@@ -245,12 +384,61 @@ func (s *Service) GetNetworkPeers(ctx context.Context, filter string) ([]*Networ
 		return nil, err
 	}
 
+	return networkPeers(peers), err
+}
+
+func networkPeers(peers []*networkPeerWithID) []*NetworkPeer {
 	ret := make([]*NetworkPeer, len(peers))
 	for i, p := range peers {
 		ret[i] = (*NetworkPeer)(p)
 	}
+	return ret
+}
+
+// MonitorNetworkPeers is like GetNetworkPeers but streams updated peer lists until the context is canceled
+func (s *Service) MonitorNetworkPeers(ctx context.Context, filter string, results chan<- []*NetworkPeer) error {
+	q := url.Values{
+		"monitor": []string{""},
+	}
+	if filter != "" {
+		q.Set("filter", filter)
+	}
 
-	return ret, err
+	u := url.URL{
+		Path:     "/network/peers",
+		RawQuery: q.Encode(),
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan []*networkPeerWithID, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- s.Client.Do(req.WithContext(ctx), ch)
+		close(ch)
+	}()
+
+	for peers := range ch {
+		select {
+		case results <- networkPeers(peers):
+		case <-ctx.Done():
+			cancel()
+			// Drain
+			for range ch {
+			}
+			<-errCh
+			return ctx.Err()
+		}
+	}
+
+	return <-errCh
 }
 
 // GetNetworkPeer returns details about a given peer.
@@ -326,6 +514,9 @@ func (s *Service) GetNetworkPeerLog(ctx context.Context, peerID string) ([]*Netw
 	if err = s.Client.Do(req, &log); err != nil {
 		return nil, err
 	}
+	for _, e := range log {
+		e.PeerID = peerID
+	}
 
 	return log, err
 }
@@ -338,7 +529,35 @@ func (s *Service) MonitorNetworkPeerLog(ctx context.Context, peerID string, resu
 		return err
 	}
 
-	return s.Client.Do(req, results)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan []*NetworkPeerLogEntry, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- s.Client.Do(req.WithContext(ctx), ch)
+		close(ch)
+	}()
+
+	for log := range ch {
+		for _, e := range log {
+			e.PeerID = peerID
+		}
+
+		select {
+		case results <- log:
+		case <-ctx.Done():
+			cancel()
+			// Drain
+			for range ch {
+			}
+			<-errCh
+			return ctx.Err()
+		}
+	}
+
+	return <-errCh
 }
 
 // GetNetworkPoints returns list the pool of known `IP:port` used for establishing P2P connections.
@@ -487,249 +706,1679 @@ func (s *Service) MonitorNetworkPointLog(ctx context.Context, address string, re
 	return s.Client.Do(req, results)
 }
 
-// GetDelegateBalance returns a delegate's balance http://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-balance
-func (s *Service) GetDelegateBalance(ctx context.Context, chainID string, blockID string, pkh string) (*big.Int, error) {
-	u := "/chains/" + chainID + "/blocks/" + blockID + "/context/delegates/" + pkh + "/balance"
+func (s *Service) getBigInt(ctx context.Context, u string) (*big.Int, error) {
 	req, err := s.Client.NewRequest(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var balance BigInt
-	if err := s.Client.Do(req, &balance); err != nil {
+	var v BigInt
+	if err := s.Client.Do(req, &v); err != nil {
 		return nil, err
 	}
 
-	return (*big.Int)(&balance.Int), nil
+	return &v.Int, nil
 }
 
-// GetContractBalance returns a contract's balance http://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-contracts-contract-id-balance
-func (s *Service) GetContractBalance(ctx context.Context, chainID string, blockID string, contractID string) (*big.Int, error) {
-	u := "/chains/" + chainID + "/blocks/" + blockID + "/context/contracts/" + contractID + "/balance"
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, u, nil)
+// GetDelegateBalance returns a delegate's balance http://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-balance
+func (s *Service) GetDelegateBalance(ctx context.Context, chainID string, blockID string, pkh string) (*big.Int, error) {
+	return s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/balance")
+}
+
+// GetDelegatedContracts returns the list of contracts delegating to the delegate
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-delegated-contracts
+func (s *Service) GetDelegatedContracts(ctx context.Context, chainID string, blockID string, pkh string) ([]string, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/delegated_contracts", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var balance BigInt
-	if err := s.Client.Do(req, &balance); err != nil {
+	var contracts []string
+	if err := s.Client.Do(req, &contracts); err != nil {
 		return nil, err
 	}
 
-	return (*big.Int)(&balance.Int), nil
+	return contracts, nil
 }
 
-// MonitorBootstrapped reads from the bootstrapped blocks stream http://tezos.gitlab.io/mainnet/api/rpc.html#get-monitor-bootstrapped
-func (s *Service) MonitorBootstrapped(ctx context.Context, results chan<- *BootstrappedBlock) error {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/monitor/bootstrapped", nil)
+// StreamDelegatedContracts is like GetDelegatedContracts but sends contracts to the channel as soon as they are decoded
+// without buffering the whole response
+func (s *Service) StreamDelegatedContracts(ctx context.Context, chainID, blockID, pkh string, out chan<- string) error {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/delegated_contracts", nil)
 	if err != nil {
 		return err
 	}
 
-	return s.Client.Do(req, results)
+	return s.Client.Do(req, &ArrayStream{Chan: out})
 }
 
-// MonitorHeads reads from the heads blocks stream https://tezos.gitlab.io/mainnet/api/rpc.html#get-monitor-heads-chain-id
-func (s *Service) MonitorHeads(ctx context.Context, chainID string, results chan<- *BlockInfo) error {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/monitor/heads/"+chainID, nil)
-	if err != nil {
-		return err
-	}
+// GetDelegateStakingBalance returns the total amount of tokens delegated to the delegate including its own balance
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-staking-balance
+func (s *Service) GetDelegateStakingBalance(ctx context.Context, chainID string, blockID string, pkh string) (*big.Int, error) {
+	return s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/staking_balance")
+}
 
-	return s.Client.Do(req, results)
+// GetDelegateFrozenBalance returns the total frozen balance of the delegate
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-frozen-balance
+func (s *Service) GetDelegateFrozenBalance(ctx context.Context, chainID string, blockID string, pkh string) (*big.Int, error) {
+	return s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/frozen_balance")
 }
 
-// GetMempoolPendingOperations returns mempool pending operations
-func (s *Service) GetMempoolPendingOperations(ctx context.Context, chainID string) (*MempoolOperations, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/mempool/pending_operations", nil)
+// GetDelegateDelegatedBalance returns the balance of all the contracts delegating to the delegate excluding its own balance
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-delegated-balance
+func (s *Service) GetDelegateDelegatedBalance(ctx context.Context, chainID string, blockID string, pkh string) (*big.Int, error) {
+	return s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/delegated_balance")
+}
+
+// GetDelegateGracePeriod returns the cycle by the end of which the delegate might be deactivated if it fails to execute any delegate action
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-grace-period
+func (s *Service) GetDelegateGracePeriod(ctx context.Context, chainID string, blockID string, pkh string) (int32, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/grace_period", nil)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	var ops MempoolOperations
-	if err := s.Client.Do(req, &ops); err != nil {
-		return nil, err
+	var cycle int32
+	if err := s.Client.Do(req, &cycle); err != nil {
+		return 0, err
 	}
 
-	return &ops, nil
+	return cycle, nil
 }
 
-// MonitorMempoolOperations monitors mempool pending operations.
-// The connection is closed after every new block.
-func (s *Service) MonitorMempoolOperations(ctx context.Context, chainID, filter string, results chan<- []*Operation) error {
-	if filter == "" {
-		filter = "applied"
+// GetDelegateDeactivated returns true if the delegate is deactivated
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-delegates-pkh-deactivated
+func (s *Service) GetDelegateDeactivated(ctx context.Context, chainID string, blockID string, pkh string) (bool, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/delegates/"+pkh+"/deactivated", nil)
+	if err != nil {
+		return false, err
 	}
 
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/mempool/monitor_operations?"+filter, nil)
-	if err != nil {
-		return err
+	var deactivated bool
+	if err := s.Client.Do(req, &deactivated); err != nil {
+		return false, err
 	}
 
-	return s.Client.Do(req, results)
+	return deactivated, nil
 }
 
-// GetInvalidBlocks lists blocks that have been declared invalid along with the errors that led to them being declared invalid.
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-chains-chain-id-invalid-blocks
-func (s *Service) GetInvalidBlocks(ctx context.Context, chainID string) ([]*InvalidBlock, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/invalid_blocks", nil)
-	if err != nil {
+// GetDelegateStakedBalance returns the amount staked by the delegate itself.
+// Returns nil on protocols preceding staking.
+// https://tezos.gitlab.io/active/rpc.html#get-block-id-context-contracts-contract-id-staked-balance
+func (s *Service) GetDelegateStakedBalance(ctx context.Context, chainID string, blockID string, pkh string) (*big.Int, error) {
+	v, err := s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+pkh+"/staked_balance")
+	if err != nil && !isHTTPStatus(err, http.StatusNotFound) {
 		return nil, err
 	}
+	return v, nil
+}
 
-	var invalidBlocks []*InvalidBlock
-	if err := s.Client.Do(req, &invalidBlocks); err != nil {
+// GetDelegateUnstakedFrozenBalance returns the delegate's unstaked amount which is still frozen.
+// Returns nil on protocols preceding staking.
+// https://tezos.gitlab.io/active/rpc.html#get-block-id-context-contracts-contract-id-unstaked-frozen-balance
+func (s *Service) GetDelegateUnstakedFrozenBalance(ctx context.Context, chainID string, blockID string, pkh string) (*big.Int, error) {
+	v, err := s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+pkh+"/unstaked_frozen_balance")
+	if err != nil && !isHTTPStatus(err, http.StatusNotFound) {
 		return nil, err
 	}
+	return v, nil
+}
 
-	return invalidBlocks, nil
+// GetContractBalance returns a contract's balance http://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-contracts-contract-id-balance
+func (s *Service) GetContractBalance(ctx context.Context, chainID string, blockID string, contractID string) (*big.Int, error) {
+	return s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/balance")
 }
 
-// GetBlock returns information about a Tezos block
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id
-func (s *Service) GetBlock(ctx context.Context, chainID, blockID string) (*Block, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID, nil)
+// GetContractBalanceTez returns the contract's balance in tez as a float for display purposes.
+// The conversion may lose precision for large balances, use GetContractBalance for exact values.
+func (s *Service) GetContractBalanceTez(ctx context.Context, chainID, blockID, contractID string) (float64, error) {
+	balance, err := s.GetContractBalance(ctx, chainID, blockID, contractID)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	var block Block
-	if err := s.Client.Do(req, &block); err != nil {
-		return nil, err
-	}
+	tez, _ := (&BigInt{Int: *balance}).Tez().Float64()
+	return tez, nil
+}
 
-	return &block, nil
+// FullBalance is a contract's spendable balance along with the amount frozen as bonds
+type FullBalance struct {
+	Balance *big.Int
+	// FrozenBonds is nil on protocols preceding Jakarta which have no frozen bonds
+	FrozenBonds *big.Int
 }
 
-// GetBallotList returns ballots casted so far during a voting period.
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-ballot-list
-func (s *Service) GetBallotList(ctx context.Context, chainID, blockID string) ([]*Ballot, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/ballot_list", nil)
+// GetContractFullBalance returns the contract's spendable balance and frozen bonds.
+// https://tezos.gitlab.io/active/rpc.html#get-block-id-context-contracts-contract-id-frozen-bonds
+func (s *Service) GetContractFullBalance(ctx context.Context, chainID, blockID, contractID string) (*FullBalance, error) {
+	balance, err := s.GetContractBalance(ctx, chainID, blockID, contractID)
 	if err != nil {
 		return nil, err
 	}
 
-	var ballots []*Ballot
-	if err := s.Client.Do(req, &ballots); err != nil {
+	bonds, err := s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/frozen_bonds")
+	if err != nil && !isHTTPStatus(err, http.StatusNotFound) {
 		return nil, err
 	}
 
-	return ballots, nil
+	return &FullBalance{
+		Balance:     balance,
+		FrozenBonds: bonds,
+	}, nil
 }
 
-// GetBallots returns sum of ballots casted so far during a voting period.
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-ballots
-func (s *Service) GetBallots(ctx context.Context, chainID, blockID string) (*Ballots, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/ballots", nil)
+// BalanceTimeSeriesSparse returns the contract's balance at each of the blocks keyed by block id.
+// The value is nil if the contract doesn't exist at the block which distinguishes it from a zero balance.
+// Up to concurrency balances are fetched simultaneously.
+func (s *Service) BalanceTimeSeriesSparse(ctx context.Context, chainID, contractID string, blockIDs []string, concurrency int) (map[string]*BigInt, error) {
+	balances := make([]*BigInt, len(blockIDs))
+
+	err := s.forEach(ctx, len(blockIDs), concurrency, func(ctx context.Context, i int) error {
+		v, err := s.GetContractBalance(ctx, chainID, blockIDs[i], contractID)
+		if err != nil {
+			if isHTTPStatus(err, http.StatusNotFound) {
+				return nil
+			}
+			return err
+		}
+		balances[i] = &BigInt{Int: *v}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var ballots Ballots
-	if err := s.Client.Do(req, &ballots); err != nil {
-		return nil, err
+	res := make(map[string]*BigInt, len(blockIDs))
+	for i, id := range blockIDs {
+		res[id] = balances[i]
 	}
 
-	return &ballots, nil
+	return res, nil
 }
 
-// GetBallotListings returns a list of delegates with their voting weight, in number of rolls.
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-listings
-func (s *Service) GetBallotListings(ctx context.Context, chainID, blockID string) ([]*BallotListing, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/listings", nil)
+const defaultConcurrency = 8
+
+// GetRollSnapshot returns the index of the roll snapshot selected for the cycle. The value is read from
+// the selected_snapshot endpoint and from the raw context on nodes which don't provide it.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-selected-snapshot
+func (s *Service) GetRollSnapshot(ctx context.Context, chainID, blockID string, cycle int32) (int, error) {
+	c := strconv.FormatInt(int64(cycle), 10)
+	u := "/chains/" + chainID + "/blocks/" + blockID + "/context"
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u+"/selected_snapshot?cycle="+c, nil)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	var listings []*BallotListing
-	if err := s.Client.Do(req, &listings); err != nil {
-		return nil, err
+	var index int
+	err = s.Client.Do(req, &index)
+	if err == nil {
+		return index, nil
+	}
+	if !isHTTPStatus(err, http.StatusNotFound) {
+		return 0, err
 	}
 
-	return listings, nil
-}
-
-// GetProposals returns a list of proposals with number of supporters.
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-proposals
-func (s *Service) GetProposals(ctx context.Context, chainID, blockID string) ([]*Proposal, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/proposals", nil)
+	req, err = s.Client.NewRequest(ctx, http.MethodGet, u+"/raw/json/cycle/"+c+"/roll_snapshot", nil)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-
-	var proposalsResp proposalsRPCResponse
-	if err := s.Client.Do(req, &proposalsResp); err != nil {
-		return nil, err
+	if err := s.Client.Do(req, &index); err != nil {
+		return 0, err
 	}
 
-	proposals := make([]*Proposal, len(proposalsResp))
+	return index, nil
+}
 
-	for i, proposalResp := range proposalsResp {
-		if len(proposalResp) == 2 {
-			proposal := &Proposal{}
-			if propHash, ok := proposalResp[0].(string); ok {
-				proposal.ProposalHash = propHash
-			} else {
-				return nil, fmt.Errorf("Malformed request ProposalHash was expected to be a string but got: %v", proposalResp[0])
-			}
-			if supporters, ok := proposalResp[1].(float64); ok {
-				proposal.SupporterCount = int(supporters)
-			} else {
-				return nil, fmt.Errorf("Malformed request SupporterCount was expected to be a float but got: %v", proposalResp[1])
-			}
-			proposals[i] = proposal
-		} else {
-			return nil, fmt.Errorf("Malformed request Proposal is expected to be tuple of size 2")
-		}
+// GetSnapshotLevel returns the level of the roll snapshot used to compute rights for the cycle.
+// The snapshot is taken within the cycle preserved_cycles + 2 cycles before. Snapshots of past cycles are
+// queried at the cycle's first block and those of future cycles at the head.
+func (s *Service) GetSnapshotLevel(ctx context.Context, chainID string, cycle int32, constants *Constants) (int32, error) {
+	snapshotCycle := cycle - int32(constants.PreservedCycles) - 2
+	if snapshotCycle < 0 {
+		return 0, fmt.Errorf("tezos: cycle %d has no roll snapshot", cycle)
 	}
 
-	return proposals, nil
-}
+	current, err := s.GetCurrentLevel(ctx, chainID, "head")
+	if err != nil {
+		return 0, err
+	}
 
-// GetCurrentProposals returns the current proposal under evaluation.
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-proposal
-func (s *Service) GetCurrentProposals(ctx context.Context, chainID, blockID string) (string, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/current_proposal", nil)
+	// Snapshots of future cycles are already selected at the head, the first block of a cycle doesn't exist yet
+	blockID := "head"
+	if int(cycle) <= current.Cycle {
+		first, _, err := s.GetCycleLevels(ctx, chainID, "head", cycle)
+		if err != nil {
+			return 0, err
+		}
+		blockID = BlockLevel(first)
+	}
+	index, err := s.GetRollSnapshot(ctx, chainID, blockID, cycle)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	var currentProposal string
-	if err := s.Client.Do(req, &currentProposal); err != nil {
-		return "", err
+	first, _, err := s.GetCycleLevels(ctx, chainID, "head", snapshotCycle)
+	if err != nil {
+		return 0, err
 	}
 
-	return currentProposal, nil
+	return first + int32(index+1)*constants.BlocksPerRollSnapshot - 1, nil
 }
 
-// GetCurrentQuorum returns the current expected quorum.
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-quorum
-func (s *Service) GetCurrentQuorum(ctx context.Context, chainID, blockID string) (int, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/current_quorum", nil)
+// GetBalanceAtCycles returns the contract's balance at the roll snapshot of each cycle keyed by cycle.
+// The value is nil if the contract doesn't exist at that block.
+func (s *Service) GetBalanceAtCycles(ctx context.Context, chainID, contractID string, cycles []int32, constants *Constants) (map[int32]*BigInt, error) {
+	blockIDs := make([]string, len(cycles))
+	err := s.forEach(ctx, len(cycles), defaultConcurrency, func(ctx context.Context, i int) error {
+		level, err := s.GetSnapshotLevel(ctx, chainID, cycles[i], constants)
+		if err != nil {
+			return err
+		}
+		blockIDs[i] = BlockLevel(level)
+		return nil
+	})
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
-	var currentQuorum int
-	if err := s.Client.Do(req, &currentQuorum); err != nil {
-		return -1, err
+	balances, err := s.BalanceTimeSeriesSparse(ctx, chainID, contractID, blockIDs, defaultConcurrency)
+	if err != nil {
+		return nil, err
 	}
 
-	return currentQuorum, nil
+	res := make(map[int32]*BigInt, len(cycles))
+	for i, c := range cycles {
+		res[c] = balances[blockIDs[i]]
+	}
+
+	return res, nil
 }
 
-// GetCurrentPeriodKind returns the current period kind
-// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-period-kind
-func (s *Service) GetCurrentPeriodKind(ctx context.Context, chainID, blockID string) (PeriodKind, error) {
-	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/current_period_kind", nil)
+// GetContractManager returns the manager's public key hash of a contract.
+// Pre-Babylon nodes expose it via /context/contracts/{id}/manager. For Babylon and later
+// it falls back to /context/contracts/{id}/manager_key and derives the hash from the revealed key.
+// An empty string is returned if the key is not revealed.
+// https://tezos.gitlab.io/athens/api/rpc.html#get-block-id-context-contracts-contract-id-manager
+func (s *Service) GetContractManager(ctx context.Context, chainID, blockID, contractID string) (string, error) {
+	u := "/chains/" + chainID + "/blocks/" + blockID + "/context/contracts/" + contractID
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u+"/manager", nil)
 	if err != nil {
 		return "", err
 	}
 
-	var periodKind PeriodKind
-	if err := s.Client.Do(req, &periodKind); err != nil {
+	var raw json.RawMessage
+	if err := s.Client.Do(req, &raw); err == nil {
+		var manager string
+		// Either a bare string or an object
+		if err := json.Unmarshal(raw, &manager); err == nil {
+			return manager, nil
+		}
+
+		var tmp struct {
+			Manager string `json:"manager"`
+		}
+		if err := json.Unmarshal(raw, &tmp); err != nil {
+			return "", err
+		}
+		return tmp.Manager, nil
+	} else if !isHTTPStatus(err, http.StatusNotFound) {
 		return "", err
 	}
 
-	return periodKind, nil
+	req, err = s.Client.NewRequest(ctx, http.MethodGet, u+"/manager_key", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var key *string
+	if err := s.Client.Do(req, &key); err != nil {
+		return "", err
+	}
+
+	if key == nil {
+		return "", nil
+	}
+
+	return PublicKeyHash(*key)
+}
+
+// GetContractDelegate returns the delegate of a contract or nil if the contract isn't delegated
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-contracts-contract-id-delegate
+func (s *Service) GetContractDelegate(ctx context.Context, chainID, blockID, contractID string) (*string, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/delegate", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var delegate *string
+	if err := s.Client.Do(req, &delegate); err != nil {
+		if isHTTPStatus(err, http.StatusNotFound) {
+			// The node replies with 404 if there is no delegate
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return delegate, nil
+}
+
+// GetContractDelegateString is like GetContractDelegate but returns an empty string if the contract isn't delegated
+func (s *Service) GetContractDelegateString(ctx context.Context, chainID, blockID, contractID string) (string, error) {
+	delegate, err := s.GetContractDelegate(ctx, chainID, blockID, contractID)
+	if err != nil || delegate == nil {
+		return "", err
+	}
+	return *delegate, nil
+}
+
+// GetNonceForLevel returns the revealed seed nonce of the block at the given level or the committed nonce hash
+// if it's not revealed yet. An empty string is returned if the nonce is forgotten.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-nonces-block-level
+func (s *Service) GetNonceForLevel(ctx context.Context, chainID, blockID string, level int32) (string, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/nonces/"+strconv.FormatInt(int64(level), 10), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var nonce struct {
+		Nonce string `json:"nonce"`
+		Hash  string `json:"hash"`
+	}
+	if err := s.Client.Do(req, &nonce); err != nil {
+		return "", err
+	}
+
+	switch {
+	case nonce.Nonce == "forgotten":
+		return "", nil
+	case nonce.Nonce != "":
+		return nonce.Nonce, nil
+	}
+	return nonce.Hash, nil
+}
+
+// GetConstants returns the protocol constants
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-constants
+func (s *Service) GetConstants(ctx context.Context, chainID, blockID string) (*Constants, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/constants", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var constants Constants
+	if err := s.Client.Do(req, &constants); err != nil {
+		return nil, err
+	}
+
+	return &constants, nil
+}
+
+// CachedConstants returns the constants of the protocol currently active on the chain.
+// Constants are fetched once per protocol and re-fetched when the head's protocol changes.
+func (s *Service) CachedConstants(ctx context.Context, chainID string) (*Constants, error) {
+	head, err := s.GetBlockHeader(ctx, chainID, "head")
+	if err != nil {
+		return nil, err
+	}
+
+	s.constantsMtx.Lock()
+	defer s.constantsMtx.Unlock()
+
+	if c, ok := s.constants[chainID]; ok && c.protocol == head.Protocol {
+		return c.constants, nil
+	}
+
+	// Query by hash to get constants of exactly the same block
+	constants, err := s.GetConstants(ctx, chainID, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.constants == nil {
+		s.constants = make(map[string]*protocolConstants)
+	}
+	s.constants[chainID] = &protocolConstants{
+		protocol:  head.Protocol,
+		constants: constants,
+	}
+
+	return constants, nil
+}
+
+// GetContractEntrypoints returns the contract's entrypoints along with their parameter types
+// https://tezos.gitlab.io/babylonnet/api/rpc.html#get-block-id-context-contracts-contract-id-entrypoints
+func (s *Service) GetContractEntrypoints(ctx context.Context, chainID, blockID, contractID string) (map[string]interface{}, error) {
+	u := "/chains/" + chainID + "/blocks/" + blockID + "/context/contracts/" + contractID + "/entrypoints"
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Entrypoints map[string]interface{} `json:"entrypoints"`
+	}
+	if err := s.Client.Do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Entrypoints, nil
+}
+
+// GetContractEntrypointType returns the parameter type of the contract's entrypoint
+// https://tezos.gitlab.io/babylonnet/api/rpc.html#get-block-id-context-contracts-contract-id-entrypoints-string
+func (s *Service) GetContractEntrypointType(ctx context.Context, chainID, blockID, contractID, entrypoint string) (map[string]interface{}, error) {
+	u := "/chains/" + chainID + "/blocks/" + blockID + "/context/contracts/" + contractID + "/entrypoints/" + entrypoint
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var typ map[string]interface{}
+	if err := s.Client.Do(req, &typ); err != nil {
+		return nil, err
+	}
+
+	return typ, nil
+}
+
+// GetBigMapValue returns the value stored in the big map under the key with the given script expression hash (expr...).
+// The hash of a packed key can be computed using ScriptExprHash.
+// https://tezos.gitlab.io/babylonnet/api/rpc.html#get-block-id-context-big-maps-big-map-id-script-expr
+func (s *Service) GetBigMapValue(ctx context.Context, chainID, blockID string, bigMapID int64, scriptExprHash string) (map[string]interface{}, error) {
+	var value map[string]interface{}
+	if err := s.getBigMapValue(ctx, chainID, blockID, bigMapID, scriptExprHash, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// getBigMapValue decodes the big map value into v
+func (s *Service) getBigMapValue(ctx context.Context, chainID, blockID string, bigMapID int64, scriptExprHash string, v interface{}) error {
+	u := "/chains/" + chainID + "/blocks/" + blockID + "/context/big_maps/" + strconv.FormatInt(bigMapID, 10) + "/" + scriptExprHash
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	return s.Client.Do(req, v)
+}
+
+// PackData returns the binary representation of the Michelson data of the given type
+// https://tezos.gitlab.io/babylonnet/api/rpc.html#post-block-id-helpers-scripts-pack-data
+func (s *Service) PackData(ctx context.Context, chainID, blockID string, data, typ map[string]interface{}) (HexBytes, error) {
+	body := struct {
+		Data map[string]interface{} `json:"data"`
+		Type map[string]interface{} `json:"type"`
+	}{
+		Data: data,
+		Type: typ,
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, "/chains/"+chainID+"/blocks/"+blockID+"/helpers/scripts/pack_data", &body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Packed HexBytes `json:"packed"`
+	}
+	if err := s.Client.Do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Packed, nil
+}
+
+// GetSaplingDiff returns the state diff of the contract's single sapling state starting from the given commitment and nullifier offsets
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-contracts-contract-id-single-sapling-get-diff
+func (s *Service) GetSaplingDiff(ctx context.Context, chainID, blockID, contractID string, offsetCommitment, offsetNullifier int64) (map[string]interface{}, error) {
+	u := url.URL{
+		Path: "/chains/" + chainID + "/blocks/" + blockID + "/context/contracts/" + contractID + "/single_sapling_get_diff",
+		RawQuery: url.Values{
+			"offset_commitment": []string{strconv.FormatInt(offsetCommitment, 10)},
+			"offset_nullifier":  []string{strconv.FormatInt(offsetNullifier, 10)},
+		}.Encode(),
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff map[string]interface{}
+	if err := s.Client.Do(req, &diff); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// IsBootstrapped returns the node's bootstrapped and synced flags. Older nodes reporting only the bootstrapped
+// flag are considered synced once bootstrapped.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-chains-chain-id-is-bootstrapped
+func (s *Service) IsBootstrapped(ctx context.Context, chainID string) (bool, bool, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/is_bootstrapped", nil)
+	if err != nil {
+		return false, false, err
+	}
+
+	var raw json.RawMessage
+	if err := s.Client.Do(req, &raw); err != nil {
+		return false, false, err
+	}
+
+	var bootstrapped bool
+	if err := json.Unmarshal(raw, &bootstrapped); err == nil {
+		return bootstrapped, bootstrapped, nil
+	}
+
+	var status struct {
+		Bootstrapped bool   `json:"bootstrapped"`
+		SyncState    string `json:"sync_state"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return false, false, err
+	}
+
+	return status.Bootstrapped, status.SyncState == "synced", nil
+}
+
+// GetChainID returns the chain id (Net...) of the chain
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-chains-chain-id-chain-id
+func (s *Service) GetChainID(ctx context.Context, chainID string) (string, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/chain_id", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	if err := s.Client.Do(req, &id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// RunView simulates a call to the TZIP-4 view entrypoint of the contract and returns the value passed to the callback
+// https://tezos.gitlab.io/mainnet/api/rpc.html#post-block-id-helpers-scripts-run-view
+func (s *Service) RunView(ctx context.Context, chainID, blockID, contractID, view string, input map[string]interface{}) (map[string]interface{}, error) {
+	id, err := s.GetChainID(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		Contract      string                 `json:"contract"`
+		Entrypoint    string                 `json:"entrypoint"`
+		Input         map[string]interface{} `json:"input"`
+		ChainID       string                 `json:"chain_id"`
+		UnparsingMode string                 `json:"unparsing_mode"`
+	}{
+		Contract:      contractID,
+		Entrypoint:    view,
+		Input:         input,
+		ChainID:       id,
+		UnparsingMode: "Readable",
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, "/chains/"+chainID+"/blocks/"+blockID+"/helpers/scripts/run_view", &body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := s.Client.Do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// GetContractCounter returns the counter of an implicit account
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-contracts-contract-id-counter
+func (s *Service) GetContractCounter(ctx context.Context, chainID, blockID, contractID string) (*big.Int, error) {
+	return s.getBigInt(ctx, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/counter")
+}
+
+// InjectOperation injects the signed operation and returns its hash
+// https://tezos.gitlab.io/mainnet/api/rpc.html#post-injection-operation
+func (s *Service) InjectOperation(ctx context.Context, chainID string, signed HexBytes) (string, error) {
+	u := url.URL{
+		Path:     "/injection/operation",
+		RawQuery: url.Values{"chain": []string{chainID}}.Encode(),
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, u.String(), signed)
+	if err != nil {
+		return "", err
+	}
+
+	var hash string
+	if err := s.Client.Do(req, &hash); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// PreapplyResult is a result of preapplication of an operation group
+type PreapplyResult struct {
+	// Operation holds contents along with their metadata. It's nil if the group was rejected.
+	Operation *Operation
+	Error     error
+}
+
+// preapplyOperation is an operation group in the format accepted by the preapply endpoint
+type preapplyOperation struct {
+	Protocol  string            `json:"protocol,omitempty"`
+	Branch    string            `json:"branch"`
+	Contents  []json.RawMessage `json:"contents"`
+	Signature string            `json:"signature"`
+}
+
+func newPreapplyOperation(op *Operation) (*preapplyOperation, error) {
+	contents, err := op.Contents.ForgeInput()
+	if err != nil {
+		return nil, err
+	}
+
+	return &preapplyOperation{
+		Protocol:  op.Protocol,
+		Branch:    op.Branch,
+		Contents:  contents,
+		Signature: op.Signature,
+	}, nil
+}
+
+func (s *Service) preapply(ctx context.Context, chainID, blockID string, ops []*preapplyOperation) ([]*Operation, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, "/chains/"+chainID+"/blocks/"+blockID+"/helpers/preapply/operations", ops)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*Operation
+	if err := s.Client.Do(req, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res) != len(ops) {
+		return nil, fmt.Errorf("tezos: %d operation groups preapplied, %d expected", len(res), len(ops))
+	}
+
+	return res, nil
+}
+
+// PreapplyOperations simulates the validation of operation groups in order and returns a result per group.
+// The node rejects the whole batch if any of the groups is invalid. In this case each group is
+// preapplied again along with the valid groups preceding it, so that each result carries its own error
+// and groups depending on their predecessors (e.g. consecutive counters) aren't reported as failed.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#post-block-id-helpers-preapply-operations
+func (s *Service) PreapplyOperations(ctx context.Context, chainID, blockID string, ops []*Operation) ([]*PreapplyResult, error) {
+	groups := make([]*preapplyOperation, len(ops))
+	for i, op := range ops {
+		g, err := newPreapplyOperation(op)
+		if err != nil {
+			return nil, err
+		}
+		groups[i] = g
+	}
+
+	results := make([]*PreapplyResult, len(ops))
+
+	applied, err := s.preapply(ctx, chainID, blockID, groups)
+	if err == nil {
+		for i, op := range applied {
+			results[i] = &PreapplyResult{Operation: op}
+		}
+		return results, nil
+	}
+
+	if _, ok := err.(RPCError); !ok {
+		return nil, err
+	}
+
+	if len(ops) == 1 {
+		results[0] = &PreapplyResult{Error: err}
+		return results, nil
+	}
+
+	// Valid groups preceding the current one
+	var prefix []*preapplyOperation
+	for i, g := range groups {
+		batch := append(prefix[:len(prefix):len(prefix)], g)
+		applied, err := s.preapply(ctx, chainID, blockID, batch)
+		if err != nil {
+			if _, ok := err.(RPCError); !ok {
+				return nil, err
+			}
+			results[i] = &PreapplyResult{Error: err}
+			continue
+		}
+		results[i] = &PreapplyResult{Operation: applied[len(applied)-1]}
+		prefix = batch
+	}
+
+	return results, nil
+}
+
+// managerLimits points to the fee and limits fields of a manager operation
+type managerLimits struct {
+	fee, gasLimit, storageLimit **BigInt
+}
+
+// cloneManagerOperationElem returns a shallow copy of the manager operation and its fee and limits fields
+func cloneManagerOperationElem(el OperationElem) (OperationElem, *managerLimits, error) {
+	switch el := el.(type) {
+	case *TransactionOperationElem:
+		tmp := *el
+		return &tmp, &managerLimits{&tmp.Fee, &tmp.GasLimit, &tmp.StorageLimit}, nil
+	case *RevealOperationElem:
+		tmp := *el
+		return &tmp, &managerLimits{&tmp.Fee, &tmp.GasLimit, &tmp.StorageLimit}, nil
+	case *DelegationOperationElem:
+		tmp := *el
+		return &tmp, &managerLimits{&tmp.Fee, &tmp.GasLimit, &tmp.StorageLimit}, nil
+	case *OriginationOperationElem:
+		tmp := *el
+		return &tmp, &managerLimits{&tmp.Fee, &tmp.GasLimit, &tmp.StorageLimit}, nil
+	}
+	return nil, nil, fmt.Errorf("tezos: %q is not a manager operation", el.OperationElemKind())
+}
+
+// simulatedConsumption returns gas and storage consumed by the simulated manager operation including its internal operations
+func simulatedConsumption(el OperationElem, constants *Constants) (gas, storage *big.Int, err error) {
+	gas, storage = new(big.Int), new(big.Int)
+	add := func(res OperationResult) error {
+		if res.OperationResultStatus() != "applied" {
+			if errs := res.OperationResultErrors(); len(errs) != 0 {
+				return errs
+			}
+			return fmt.Errorf("tezos: simulated operation status: %s", res.OperationResultStatus())
+		}
+
+		var consumed, paid *BigInt
+		var originated int
+		switch res := res.(type) {
+		case *TransactionOperationResult:
+			consumed, paid, originated = res.ConsumedGas, res.PaidStorageSizeDiff, len(res.OriginatedContracts)
+			// A new implicit account is paid for as an origination
+			if res.AllocatedDestinationContract {
+				originated++
+			}
+		case *OriginationOperationResult:
+			consumed, paid, originated = res.ConsumedGas, res.PaidStorageSizeDiff, len(res.OriginatedContracts)
+		case *DelegationOperationResult:
+			consumed = res.ConsumedGas
+		}
+
+		if consumed != nil {
+			gas.Add(gas, &consumed.Int)
+		}
+		if paid != nil {
+			storage.Add(storage, &paid.Int)
+		}
+		storage.Add(storage, big.NewInt(int64(originated*constants.OriginationSize)))
+		return nil
+	}
+
+	op := Operation{Contents: OperationElements{el}}
+	for _, res := range op.operationResults() {
+		if err := add(res); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return gas, storage, nil
+}
+
+// EstimateFees simulates the manager operations using the run_operation endpoint and returns copies of them
+// with gas and storage limits set to the simulated consumption plus safety margins of config
+// and fees set to the minimal fees accepted by the mempool configuration of config plus the fee buffer.
+// Nil config means DefaultFeeConfig.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#post-block-id-helpers-scripts-run-operation
+func (s *Service) EstimateFees(ctx context.Context, chainID, blockID string, op OperationElements, branch string, config *FeeConfig) (OperationElements, error) {
+	if len(op) == 0 {
+		return nil, fmt.Errorf("tezos: no operations to estimate")
+	}
+	if config == nil {
+		config = DefaultFeeConfig()
+	}
+
+	constants, err := s.GetConstants(ctx, chainID, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.GetChainID(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Simulate with the maximum limits allowed. The whole group must fit into the block gas limit
+	gasLimit := constants.HardGasLimitPerOperation
+	if perBlock := constants.HardGasLimitPerBlock / int64(len(op)); perBlock != 0 && perBlock < gasLimit {
+		gasLimit = perBlock
+	}
+
+	contents := make(OperationElements, len(op))
+	limits := make([]*managerLimits, len(op))
+	for i, el := range op {
+		if contents[i], limits[i], err = cloneManagerOperationElem(el); err != nil {
+			return nil, err
+		}
+		*limits[i].fee = &BigInt{}
+		*limits[i].gasLimit = &BigInt{Int: *big.NewInt(gasLimit)}
+		*limits[i].storageLimit = &BigInt{Int: *big.NewInt(constants.HardStorageLimitPerOperation)}
+	}
+
+	// The signature isn't checked by the simulation
+	signature := base58CheckEncode(prefixGenericSignature, make([]byte, 64))
+
+	simOp, err := newPreapplyOperation(&Operation{Branch: branch, Contents: contents, Signature: signature})
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		Operation *preapplyOperation `json:"operation"`
+		ChainID   string             `json:"chain_id"`
+	}{
+		Operation: simOp,
+		ChainID:   id,
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, "/chains/"+chainID+"/blocks/"+blockID+"/helpers/scripts/run_operation", &body)
+	if err != nil {
+		return nil, err
+	}
+
+	var simulated Operation
+	if err := s.Client.Do(req, &simulated); err != nil {
+		return nil, err
+	}
+
+	if len(simulated.Contents) != len(contents) {
+		return nil, fmt.Errorf("tezos: %d operations simulated, %d expected", len(simulated.Contents), len(contents))
+	}
+
+	// Branch and signature bytes are shared between all operations in the group
+	overhead := (32 + 64 + len(contents) - 1) / len(contents)
+
+	for i, el := range simulated.Contents {
+		gas, storage, err := simulatedConsumption(el, constants)
+		if err != nil {
+			return nil, err
+		}
+
+		l := limits[i]
+		*l.gasLimit = &BigInt{Int: *config.GasLimit(gas)}
+		*l.storageLimit = &BigInt{Int: *config.StorageLimit(storage)}
+
+		// The fee affects the operation size so iterate until it's stable
+		for {
+			var buf bytes.Buffer
+			if err := forgeOperationElem(&buf, contents[i]); err != nil {
+				return nil, err
+			}
+			fee := config.Fee(buf.Len()+overhead, &(*l.gasLimit).Int)
+			if fee.Cmp(&(*l.fee).Int) == 0 {
+				break
+			}
+			*l.fee = &BigInt{Int: *fee}
+		}
+	}
+
+	return contents, nil
+}
+
+// InjectWithCounterRetry fetches the source's counter, calls build with the next counter value
+// to get the signed operation and injects it. If the node rejects the operation because of a counter
+// error the counter is re-fetched and the operation is rebuilt and injected once more.
+func (s *Service) InjectWithCounterRetry(ctx context.Context, chainID, source string, build func(ctx context.Context, counter *big.Int) (HexBytes, error)) (string, error) {
+	for i := 0; ; i++ {
+		counter, err := s.GetContractCounter(ctx, chainID, "head", source)
+		if err != nil {
+			return "", err
+		}
+
+		signed, err := build(ctx, counter.Add(counter, big.NewInt(1)))
+		if err != nil {
+			return "", err
+		}
+
+		hash, err := s.InjectOperation(ctx, chainID, signed)
+		if err != nil && i == 0 && rpcErrors(err).IsCounterError() {
+			continue
+		}
+		return hash, err
+	}
+}
+
+// MonitorBootstrapped reads from the bootstrapped blocks stream http://tezos.gitlab.io/mainnet/api/rpc.html#get-monitor-bootstrapped
+func (s *Service) MonitorBootstrapped(ctx context.Context, results chan<- *BootstrappedBlock) error {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/monitor/bootstrapped", nil)
+	if err != nil {
+		return err
+	}
+
+	return s.Client.Do(req, results)
+}
+
+// MonitorHeads reads from the heads blocks stream https://tezos.gitlab.io/mainnet/api/rpc.html#get-monitor-heads-chain-id
+func (s *Service) MonitorHeads(ctx context.Context, chainID string, results chan<- *BlockInfo) error {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/monitor/heads/"+chainID, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.Client.Do(req, results)
+}
+
+// ValidBlock is an element of the valid blocks stream. Unlike RawBlockHeader it carries the chain id
+// and the hash which are needed to tell blocks of the main and test chains apart, and the protocol data
+// is left unparsed as the node streams it as an opaque blob.
+type ValidBlock struct {
+	ChainID string `json:"chain_id" yaml:"chain_id"`
+	BlockInfo
+}
+
+// ValidBlocksOptions holds optional filters of the valid blocks stream. Each list is matched against any of its members
+type ValidBlocksOptions struct {
+	Protocol     []string
+	NextProtocol []string
+	Chain        []string
+}
+
+// MonitorValidBlocks reads from the stream of all valid blocks including ones from test chains.
+// Blocks are delivered as ValidBlock rather than RawBlockHeader to keep the chain id and the hash of each block.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-monitor-valid-blocks
+func (s *Service) MonitorValidBlocks(ctx context.Context, opts *ValidBlocksOptions, results chan<- *ValidBlock) error {
+	u := url.URL{
+		Path: "/monitor/valid_blocks",
+	}
+
+	if opts != nil {
+		q := url.Values{}
+		for _, p := range opts.Protocol {
+			q.Add("protocol", p)
+		}
+		for _, p := range opts.NextProtocol {
+			q.Add("next_protocol", p)
+		}
+		for _, c := range opts.Chain {
+			q.Add("chain", c)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	return s.Client.Do(req, results)
+}
+
+// GetMempoolPendingOperations returns mempool pending operations
+func (s *Service) GetMempoolPendingOperations(ctx context.Context, chainID string) (*MempoolOperations, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/mempool/pending_operations", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops MempoolOperations
+	if err := s.Client.Do(req, &ops); err != nil {
+		return nil, err
+	}
+
+	return &ops, nil
+}
+
+// MonitorMempoolOperations monitors mempool pending operations.
+// The connection is closed after every new block.
+func (s *Service) MonitorMempoolOperations(ctx context.Context, chainID, filter string, results chan<- []*Operation) error {
+	if filter == "" {
+		filter = "applied"
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/mempool/monitor_operations?"+filter, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.Client.Do(req, results)
+}
+
+// GetInvalidBlocks lists blocks that have been declared invalid along with the errors that led to them being declared invalid.
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-chains-chain-id-invalid-blocks
+func (s *Service) GetInvalidBlocks(ctx context.Context, chainID string) ([]*InvalidBlock, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/invalid_blocks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var invalidBlocks []*InvalidBlock
+	if err := s.Client.Do(req, &invalidBlocks); err != nil {
+		return nil, err
+	}
+
+	return invalidBlocks, nil
+}
+
+// GetActiveChains returns the chains the node is currently running. Only the first snapshot of the stream is read.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-monitor-active-chains
+func (s *Service) GetActiveChains(ctx context.Context) ([]*ActiveChain, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/monitor/active_chains", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var chains []*ActiveChain
+	if err := s.Client.Do(req, &chains); err != nil {
+		return nil, err
+	}
+
+	return chains, nil
+}
+
+// GetBlocks lists the hashes of the known heads each followed by its predecessors
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-chains-chain-id-blocks
+func (s *Service) GetBlocks(ctx context.Context, chainID string, opts *BlocksOptions) ([][]string, error) {
+	u := url.URL{
+		Path: "/chains/" + chainID + "/blocks",
+	}
+
+	if opts != nil {
+		q := url.Values{}
+		if opts.Length != 0 {
+			q.Set("length", strconv.Itoa(opts.Length))
+		}
+		for _, h := range opts.Head {
+			q.Add("head", h)
+		}
+		if !opts.MinDate.IsZero() {
+			q.Set("min_date", strconv.FormatInt(opts.MinDate.Unix(), 10))
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks [][]string
+	if err := s.Client.Do(req, &blocks); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// MetadataMode controls the inclusion of block and operation metadata into the node's response
+type MetadataMode string
+
+const (
+	// MetadataDefault leaves the decision to the node
+	MetadataDefault MetadataMode = ""
+	// MetadataNever omits metadata which is much cheaper for the node
+	MetadataNever MetadataMode = "never"
+	// MetadataAlways forces metadata to be included even if the node wouldn't do it by default
+	MetadataAlways MetadataMode = "always"
+)
+
+// GetBlock returns information about a Tezos block
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id
+func (s *Service) GetBlock(ctx context.Context, chainID, blockID string) (*Block, error) {
+	return s.GetBlockWithMetadata(ctx, chainID, blockID, MetadataDefault)
+}
+
+// GetBlockWithMetadata is like GetBlock but allows to control the inclusion of metadata
+func (s *Service) GetBlockWithMetadata(ctx context.Context, chainID, blockID string, metadata MetadataMode) (*Block, error) {
+	u := url.URL{
+		Path: "/chains/" + chainID + "/blocks/" + blockID,
+	}
+
+	if metadata != MetadataDefault {
+		q := url.Values{
+			"metadata": []string{string(metadata)},
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var block Block
+	if err := s.Client.Do(req, &block); err != nil {
+		return nil, err
+	}
+
+	return &block, nil
+}
+
+// GetBlockByLevel returns the block at the given level of the main chain.
+// An error is returned if the level is above the current head.
+func (s *Service) GetBlockByLevel(ctx context.Context, chainID string, level int32) (*Block, error) {
+	block, err := s.GetBlock(ctx, chainID, BlockLevel(level))
+	if err == nil || !isHTTPStatus(err, http.StatusNotFound) {
+		return block, err
+	}
+
+	head, herr := s.GetBlockHeader(ctx, chainID, BlockHead())
+	if herr != nil {
+		return nil, err
+	}
+
+	if int(level) > head.Level {
+		return nil, fmt.Errorf("tezos: level %d is above the current head level %d", level, head.Level)
+	}
+
+	return nil, err
+}
+
+// GetBlockMetadata returns the metadata of a Tezos block
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-metadata
+func (s *Service) GetBlockMetadata(ctx context.Context, chainID, blockID string) (*BlockHeaderMetadata, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/metadata", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata BlockHeaderMetadata
+	if err := s.Client.Do(req, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// GetBlockCycle returns the cycle of a Tezos block
+func (s *Service) GetBlockCycle(ctx context.Context, chainID, blockID string) (int32, error) {
+	metadata, err := s.GetBlockMetadata(ctx, chainID, blockID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(metadata.Level.Cycle), nil
+}
+
+// GetBlockHeader returns the header of a Tezos block
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-header
+func (s *Service) GetBlockHeader(ctx context.Context, chainID, blockID string) (*BlockHeader, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/header", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var header BlockHeader
+	if err := s.Client.Do(req, &header); err != nil {
+		return nil, err
+	}
+
+	return &header, nil
+}
+
+// GetBlockProtocols returns the current and the next protocol of the block. Like other block scoped methods
+// it takes a block id string which can be built with BlockLevel, BlockHash and friends.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-protocols
+func (s *Service) GetBlockProtocols(ctx context.Context, chainID, blockID string) (*BlockProtocols, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/protocols", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var protocols BlockProtocols
+	if err := s.Client.Do(req, &protocols); err != nil {
+		return nil, err
+	}
+
+	return &protocols, nil
+}
+
+// SelectBranch returns the hash of the block backoffBlocks levels behind the current head to be used as an operation branch.
+// Branching off an older block makes the operation resilient against reorganisations of the chain tip
+// while backoffBlocks must stay below the max_operations_ttl of the head.
+func (s *Service) SelectBranch(ctx context.Context, chainID string, backoffBlocks int) (string, error) {
+	if backoffBlocks < 0 {
+		return "", fmt.Errorf("tezos: negative branch backoff: %d", backoffBlocks)
+	}
+
+	head, err := s.GetBlockHeader(ctx, chainID, BlockHead())
+	if err != nil {
+		return "", err
+	}
+
+	metadata, err := s.GetBlockMetadata(ctx, chainID, BlockHash(head.Hash))
+	if err != nil {
+		return "", err
+	}
+
+	if backoffBlocks >= metadata.MaxOperationsTTL {
+		return "", fmt.Errorf("tezos: branch backoff %d exceeds the operation TTL of %d blocks", backoffBlocks, metadata.MaxOperationsTTL)
+	}
+
+	if backoffBlocks == 0 {
+		return head.Hash, nil
+	}
+
+	branch, err := s.GetBlockHeader(ctx, chainID, BlockRelativeTo(head.Hash, -backoffBlocks))
+	if err != nil {
+		return "", err
+	}
+
+	return branch.Hash, nil
+}
+
+// GetCurrentLevel returns the level info of a block
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-helpers-current-level
+func (s *Service) GetCurrentLevel(ctx context.Context, chainID, blockID string) (*BlockHeaderMetadataLevel, error) {
+	return s.GetCurrentLevelWithOffset(ctx, chainID, blockID, 0)
+}
+
+// GetCurrentLevelWithOffset is like GetCurrentLevel but returns the level info of a block offset levels away
+func (s *Service) GetCurrentLevelWithOffset(ctx context.Context, chainID, blockID string, offset int32) (*BlockHeaderMetadataLevel, error) {
+	u := url.URL{
+		Path: "/chains/" + chainID + "/blocks/" + blockID + "/helpers/current_level",
+	}
+
+	if offset != 0 {
+		q := url.Values{
+			"offset": []string{strconv.FormatInt(int64(offset), 10)},
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var level BlockHeaderMetadataLevel
+	if err := s.Client.Do(req, &level); err != nil {
+		return nil, err
+	}
+
+	return &level, nil
+}
+
+// GetBlockOperations returns operations contained in a block grouped by validation pass
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-operations
+func (s *Service) GetBlockOperations(ctx context.Context, chainID, blockID string) ([][]*Operation, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/operations", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops [][]*Operation
+	if err := s.Client.Do(req, &ops); err != nil {
+		return nil, err
+	}
+
+	return ops, nil
+}
+
+// StreamOperations sends operations of blocks in the range [from, to] containing at least one element
+// of the given kind to the channel in order of their levels. Empty kind matches all operations.
+// The channel isn't closed.
+func (s *Service) StreamOperations(ctx context.Context, chainID string, from, to int32, kind string, out chan<- *Operation) error {
+	for level := from; level <= to; level++ {
+		ops, err := s.GetBlockOperations(ctx, chainID, BlockLevel(level))
+		if err != nil {
+			return err
+		}
+
+		for _, pass := range ops {
+			for _, op := range pass {
+				if kind != "" && !op.ContainsKind(kind) {
+					continue
+				}
+
+				select {
+				case out <- op:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// cachedOperation is a block containing the operation remembered by FindOperation
+type cachedOperation struct {
+	chainID   string
+	blockHash string
+	level     int32
+}
+
+type cachedBlock struct {
+	chainID string
+	hash    string
+	level   int32
+}
+
+func (s *Service) operationCache() (ops, blocks *lruCache) {
+	s.opCacheOnce.Do(func() {
+		s.opCache = newLRUCache(defaultOperationCacheSize)
+		s.opBlocksCache = newLRUCache(defaultBlockCacheSize)
+	})
+	return s.opCache, s.opBlocksCache
+}
+
+// InvalidateOperationCache makes FindOperation forget operations of the chain included at or above the level,
+// e.g. after a reorganization.
+func (s *Service) InvalidateOperationCache(chainID string, level int32) {
+	ops, blocks := s.operationCache()
+	ops.removeFunc(func(key string, value interface{}) bool {
+		op := value.(*cachedOperation)
+		return op.chainID == chainID && op.level >= level
+	})
+	blocks.removeFunc(func(key string, value interface{}) bool {
+		b := value.(*cachedBlock)
+		return b.chainID == chainID && b.level >= level
+	})
+}
+
+// FindOperation returns the hash of the block in the range [from, to] containing the operation.
+// An empty string is returned if the operation is not found. Hashes of operations of scanned blocks
+// are remembered so repeated lookups don't fetch blocks again. A scanned block different from the remembered
+// one at the same level indicates a reorganization so everything remembered at or above that level is dropped.
+func (s *Service) FindOperation(ctx context.Context, chainID, opHash string, from, to int32) (string, error) {
+	cache, blocks := s.operationCache()
+	if v, ok := cache.get(chainID + "/" + opHash); ok {
+		if op := v.(*cachedOperation); op.level >= from && op.level <= to {
+			return op.blockHash, nil
+		}
+	}
+
+	for level := from; level <= to; level++ {
+		block, err := s.GetBlock(ctx, chainID, BlockLevel(level))
+		if err != nil {
+			return "", err
+		}
+
+		blockKey := chainID + "/" + strconv.FormatInt(int64(level), 10)
+		if v, ok := blocks.get(blockKey); ok && v.(*cachedBlock).hash != block.Hash {
+			s.InvalidateOperationCache(chainID, level)
+		}
+		blocks.add(blockKey, &cachedBlock{chainID: chainID, hash: block.Hash, level: level})
+
+		var found bool
+		for _, pass := range block.Operations {
+			for _, op := range pass {
+				cache.add(chainID+"/"+op.Hash, &cachedOperation{chainID: chainID, blockHash: block.Hash, level: level})
+				if op.Hash == opHash {
+					found = true
+				}
+			}
+		}
+
+		if found {
+			return block.Hash, nil
+		}
+	}
+
+	return "", nil
+}
+
+// GetCycleLevels returns the first and the last levels of the cycle as known to the node at the given block.
+// The range is resolved by the node so changes of blocks_per_cycle across protocols are taken into account.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-helpers-levels-in-current-cycle
+func (s *Service) GetCycleLevels(ctx context.Context, chainID, blockID string, cycle int32) (first, last int32, err error) {
+	level, err := s.GetCurrentLevel(ctx, chainID, blockID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	u := url.URL{
+		Path: "/chains/" + chainID + "/blocks/" + blockID + "/helpers/levels_in_current_cycle",
+	}
+	if offset := int64(cycle) - int64(level.Cycle); offset != 0 {
+		q := url.Values{
+			"offset": []string{strconv.FormatInt(offset, 10)},
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var levels struct {
+		First int32 `json:"first"`
+		Last  int32 `json:"last"`
+	}
+	if err := s.Client.Do(req, &levels); err != nil {
+		return 0, 0, err
+	}
+
+	return levels.First, levels.Last, nil
+}
+
+// GetCycleHeaders returns headers of all blocks of the cycle in order of their levels.
+// The level range is resolved by the node using GetCycleLevels at the head block.
+// Up to concurrency headers are fetched simultaneously.
+func (s *Service) GetCycleHeaders(ctx context.Context, chainID string, cycle int32, concurrency int) ([]*RawBlockHeader, error) {
+	first, last, err := s.GetCycleLevels(ctx, chainID, "head", cycle)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*RawBlockHeader, last-first+1)
+
+	err = s.forEach(ctx, len(headers), concurrency, func(ctx context.Context, i int) error {
+		h, err := s.GetBlockHeader(ctx, chainID, BlockLevel(first+int32(i)))
+		if err != nil {
+			return err
+		}
+		headers[i] = &h.RawBlockHeader
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return headers, nil
+}
+
+// GetBallotList returns ballots casted so far during a voting period.
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-ballot-list
+func (s *Service) GetBallotList(ctx context.Context, chainID, blockID string) ([]*Ballot, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/ballot_list", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ballots []*Ballot
+	if err := s.Client.Do(req, &ballots); err != nil {
+		return nil, err
+	}
+
+	return ballots, nil
+}
+
+// GetBallots returns sum of ballots casted so far during a voting period.
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-ballots
+func (s *Service) GetBallots(ctx context.Context, chainID, blockID string) (*Ballots, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/ballots", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ballots Ballots
+	if err := s.Client.Do(req, &ballots); err != nil {
+		return nil, err
+	}
+
+	return &ballots, nil
+}
+
+// GetBallotListings returns a list of delegates with their voting weight, in number of rolls.
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-listings
+func (s *Service) GetBallotListings(ctx context.Context, chainID, blockID string) ([]*BallotListing, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/listings", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var listings []*BallotListing
+	if err := s.Client.Do(req, &listings); err != nil {
+		return nil, err
+	}
+
+	return listings, nil
+}
+
+// GetProposals returns a list of proposals with number of supporters.
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-proposals
+func (s *Service) GetProposals(ctx context.Context, chainID, blockID string) ([]*Proposal, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/proposals", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var proposalsResp proposalsRPCResponse
+	if err := s.Client.Do(req, &proposalsResp); err != nil {
+		return nil, err
+	}
+
+	proposals := make([]*Proposal, len(proposalsResp))
+
+	for i, proposalResp := range proposalsResp {
+		if len(proposalResp) == 2 {
+			proposal := &Proposal{}
+			if propHash, ok := proposalResp[0].(string); ok {
+				proposal.ProposalHash = propHash
+			} else {
+				return nil, fmt.Errorf("Malformed request ProposalHash was expected to be a string but got: %v", proposalResp[0])
+			}
+			if supporters, ok := proposalResp[1].(float64); ok {
+				proposal.SupporterCount = int(supporters)
+			} else {
+				return nil, fmt.Errorf("Malformed request SupporterCount was expected to be a float but got: %v", proposalResp[1])
+			}
+			proposals[i] = proposal
+		} else {
+			return nil, fmt.Errorf("Malformed request Proposal is expected to be tuple of size 2")
+		}
+	}
+
+	return proposals, nil
+}
+
+// GetCurrentProposals returns the current proposal under evaluation.
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-proposal
+func (s *Service) GetCurrentProposals(ctx context.Context, chainID, blockID string) (string, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/current_proposal", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var currentProposal string
+	if err := s.Client.Do(req, &currentProposal); err != nil {
+		return "", err
+	}
+
+	return currentProposal, nil
+}
+
+// GetCurrentQuorum returns the current expected quorum.
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-quorum
+func (s *Service) GetCurrentQuorum(ctx context.Context, chainID, blockID string) (int, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/current_quorum", nil)
+	if err != nil {
+		return -1, err
+	}
+
+	var currentQuorum int
+	if err := s.Client.Do(req, &currentQuorum); err != nil {
+		return -1, err
+	}
+
+	return currentQuorum, nil
+}
+
+// GetCurrentPeriodKind returns the current period kind
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-period-kind
+func (s *Service) GetCurrentPeriodKind(ctx context.Context, chainID, blockID string) (PeriodKind, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/votes/current_period_kind", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var periodKind PeriodKind
+	if err := s.Client.Do(req, &periodKind); err != nil {
+		return "", err
+	}
+
+	return periodKind, nil
+}
+
+// GetRawContextBytes returns the subtree of the raw context under the slash separated key. Leaves are hex encoded.
+// The key must name a directory. Positive depth limits the depth of the returned subtree
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-raw-bytes
+func (s *Service) GetRawContextBytes(ctx context.Context, chainID, blockID, key string, depth int) (map[string]interface{}, error) {
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+
+	u := "/chains/" + chainID + "/blocks/" + blockID + "/context/raw/bytes/" + strings.Join(segments, "/")
+	if depth > 0 {
+		u += "?" + url.Values{"depth": []string{strconv.Itoa(depth)}}.Encode()
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree map[string]interface{}
+	if err := s.Client.Do(req, &tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// GetRaw performs a GET request to an arbitrary RPC path and returns the undecoded response.
+// It's intended for endpoints not wrapped by the library yet
+func (s *Service) GetRaw(ctx context.Context, path string, query url.Values) (json.RawMessage, error) {
+	u := url.URL{
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res json.RawMessage
+	if err := s.Client.Do(req, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// PostRaw performs a POST request with the JSON encoded body to an arbitrary RPC path and returns the undecoded response.
+// It's intended for endpoints not wrapped by the library yet
+func (s *Service) PostRaw(ctx context.Context, path string, body interface{}) (json.RawMessage, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var res json.RawMessage
+	if err := s.Client.Do(req, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
 }
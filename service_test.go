@@ -1,11 +1,17 @@
 package tezos
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -91,7 +97,7 @@ func TestServiceGetMethods(t *testing.T) {
 			respFixture:     "fixtures/network/peer_log.json",
 			respContentType: "application/json",
 			expectedPath:    "/network/peers/idrPSsREFE1MV1161ybEpaebFwgYWE/log",
-			expectedValue:   []*NetworkPeerLogEntry{&NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T15:35:17Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T15:35:19Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T18:02:51Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:14Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:15Z")}},
+			expectedValue:   []*NetworkPeerLogEntry{&NetworkPeerLogEntry{PeerID: "idrPSsREFE1MV1161ybEpaebFwgYWE", NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T15:35:17Z")}, &NetworkPeerLogEntry{PeerID: "idrPSsREFE1MV1161ybEpaebFwgYWE", NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T15:35:19Z")}, &NetworkPeerLogEntry{PeerID: "idrPSsREFE1MV1161ybEpaebFwgYWE", NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T18:02:51Z")}, &NetworkPeerLogEntry{PeerID: "idrPSsREFE1MV1161ybEpaebFwgYWE", NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:14Z")}, &NetworkPeerLogEntry{PeerID: "idrPSsREFE1MV1161ybEpaebFwgYWE", NetworkAddress: NetworkAddress{Addr: "::ffff:13.81.43.51", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:15Z")}},
 		},
 		{
 			get: func(s *Service) (interface{}, error) {
@@ -110,7 +116,7 @@ func TestServiceGetMethods(t *testing.T) {
 			respFixture:     "fixtures/network/peer_log.chunked",
 			respContentType: "application/json",
 			expectedPath:    "/network/peers/idsBATisQfJu7d6vCLY4CP66dKj7CQ/log",
-			expectedValue:   [][]*NetworkPeerLogEntry{[]*NetworkPeerLogEntry{&NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T15:20:14Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T15:20:14Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T16:30:08Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T16:39:20Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T16:39:20Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T19:48:58Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:30Z")}, &NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:30Z")}}, []*NetworkPeerLogEntry{&NetworkPeerLogEntry{NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T22:25:07Z")}}},
+			expectedValue:   [][]*NetworkPeerLogEntry{[]*NetworkPeerLogEntry{&NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T15:20:14Z")}, &NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T15:20:14Z")}, &NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T16:30:08Z")}, &NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T16:39:20Z")}, &NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T16:39:20Z")}, &NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T19:48:58Z")}, &NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "incoming_request", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:30Z")}, &NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "connection_established", Timestamp: timeMustUnmarshalText("2018-11-13T20:56:30Z")}}, []*NetworkPeerLogEntry{&NetworkPeerLogEntry{PeerID: "idsBATisQfJu7d6vCLY4CP66dKj7CQ", NetworkAddress: NetworkAddress{Addr: "::ffff:51.15.242.114", Port: 9732}, Kind: "external_disconnection", Timestamp: timeMustUnmarshalText("2018-11-13T22:25:07Z")}}},
 		},
 		{
 			get:             func(s *Service) (interface{}, error) { return s.GetNetworkPoints(ctx, "") },
@@ -183,6 +189,79 @@ func TestServiceGetMethods(t *testing.T) {
 			expectedPath:    "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/balance",
 			expectedValue:   big.NewInt(13490453135591),
 		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateStakingBalance(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegate_staking_balance.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/staking_balance",
+			expectedValue:   big.NewInt(54208713416812),
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateFrozenBalance(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegate_frozen_balance.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/frozen_balance",
+			expectedValue:   big.NewInt(3062519745084),
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateDelegatedBalance(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegate_delegated_balance.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/delegated_balance",
+			expectedValue:   big.NewInt(40718260281221),
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateGracePeriod(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegate_grace_period.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/grace_period",
+			expectedValue:   int32(165),
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateDeactivated(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegate_deactivated.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/deactivated",
+			expectedValue:   false,
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateStakedBalance(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegate_staked_balance.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/contracts/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/staked_balance",
+			expectedValue:   big.NewInt(6000000000000),
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateUnstakedFrozenBalance(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegate_unstaked_frozen_balance.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/contracts/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/unstaked_frozen_balance",
+			expectedValue:   big.NewInt(125000000),
+		},
+		// Old protocols don't have staking
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegateStakedBalance(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respStatus:    404,
+			respFixture:   "fixtures/empty.json",
+			expectedPath:  "/chains/main/blocks/head/context/contracts/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/staked_balance",
+			expectedValue: (*big.Int)(nil),
+		},
 		{
 			get: func(s *Service) (interface{}, error) {
 				return s.GetContractBalance(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
@@ -192,6 +271,319 @@ func TestServiceGetMethods(t *testing.T) {
 			expectedPath:    "/chains/main/blocks/head/context/contracts/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/balance",
 			expectedValue:   big.NewInt(4700354460878),
 		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetContractBalanceTez(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/contract_balance.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/contracts/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/balance",
+			expectedValue:   4700354.460878,
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetConstants(ctx, "main", "head")
+			},
+			respFixture:     "fixtures/block/constants.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/constants",
+			expectedValue: &Constants{
+				ProofOfWorkNonceSize:         8,
+				NonceLength:                  32,
+				MaxRevelationsPerBlock:       32,
+				MaxOperationDataLength:       16384,
+				MaxProposalsPerDelegate:      20,
+				PreservedCycles:              5,
+				BlocksPerCycle:               4096,
+				BlocksPerCommitment:          32,
+				BlocksPerRollSnapshot:        256,
+				BlocksPerVotingPeriod:        32768,
+				TimeBetweenBlocks:            []*BigInt{bigIntMust("60"), bigIntMust("40")},
+				EndorsersPerBlock:            32,
+				HardGasLimitPerOperation:     800000,
+				HardGasLimitPerBlock:         8000000,
+				ProofOfWorkThreshold:         70368744177663,
+				TokensPerRoll:                8000000000,
+				MichelsonMaximumTypeSize:     1000,
+				SeedNonceRevelationTip:       125000,
+				OriginationSize:              257,
+				BlockSecurityDeposit:         512000000,
+				EndorsementSecurityDeposit:   64000000,
+				BlockReward:                  16000000,
+				EndorsementReward:            2000000,
+				CostPerByte:                  1000,
+				HardStorageLimitPerOperation: 60000,
+				TestChainDuration:            1966080,
+				QuorumMin:                    2000,
+				QuorumMax:                    7000,
+				MinProposalQuorum:            500,
+				InitialEndorsers:             24,
+				DelayPerMissingEndorsement:   8,
+			},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetContractEntrypoints(ctx, "main", "head", "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv")
+			},
+			respFixture:     "fixtures/contracts/entrypoints.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/contracts/KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv/entrypoints",
+			expectedValue: map[string]interface{}{
+				"default": map[string]interface{}{"prim": "unit"},
+				"mint": map[string]interface{}{"prim": "pair", "args": []interface{}{
+					map[string]interface{}{"prim": "address", "annots": []interface{}{":to"}},
+					map[string]interface{}{"prim": "nat", "annots": []interface{}{":value"}},
+				}},
+				"transfer": map[string]interface{}{"prim": "pair", "args": []interface{}{
+					map[string]interface{}{"prim": "address", "annots": []interface{}{":from"}},
+					map[string]interface{}{"prim": "pair", "args": []interface{}{
+						map[string]interface{}{"prim": "address", "annots": []interface{}{":to"}},
+						map[string]interface{}{"prim": "nat", "annots": []interface{}{":value"}},
+					}},
+				}},
+			},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetContractEntrypointType(ctx, "main", "head", "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv", "mint")
+			},
+			respFixture:     "fixtures/contracts/entrypoint.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/contracts/KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv/entrypoints/mint",
+			expectedValue: map[string]interface{}{"prim": "pair", "args": []interface{}{
+				map[string]interface{}{"prim": "address", "annots": []interface{}{":to"}},
+				map[string]interface{}{"prim": "nat", "annots": []interface{}{":value"}},
+			}},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetBigMapValue(ctx, "main", "head", 31, "exprtiRSZkLKYRess9GZ3ryb4cVQD36WLo3oFcLBTxNfDCfNzG2H7S")
+			},
+			respFixture:     "fixtures/big_maps/value.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/big_maps/31/exprtiRSZkLKYRess9GZ3ryb4cVQD36WLo3oFcLBTxNfDCfNzG2H7S",
+			expectedValue:   map[string]interface{}{"prim": "Pair", "args": []interface{}{map[string]interface{}{"int": "1000000"}, []interface{}{}}},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetContractManager(ctx, "main", "head", "KT1XBBwMkKH9ZrTaAv4BvQzxfVFaGXqHRjoe")
+			},
+			respFixture:     "fixtures/contracts/manager.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/contracts/KT1XBBwMkKH9ZrTaAv4BvQzxfVFaGXqHRjoe/manager",
+			expectedValue:   "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetBlockCycle(ctx, "main", BlockHead())
+			},
+			respFixture:     "fixtures/block/metadata.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/metadata",
+			expectedValue:   int32(106),
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetCurrentLevel(ctx, "main", "head")
+			},
+			respFixture:     "fixtures/block/current_level.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/helpers/current_level",
+			expectedValue:   &BlockHeaderMetadataLevel{Level: 1466368, LevelPosition: 1466367, Cycle: 357, CyclePosition: 4095, VotingPeriod: 44, VotingPeriodPosition: 20479, ExpectedCommitment: true},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetCurrentLevelWithOffset(ctx, "main", "head", 4096)
+			},
+			respFixture:     "fixtures/block/current_level.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/helpers/current_level",
+			expectedQuery:   "offset=4096",
+			expectedValue:   &BlockHeaderMetadataLevel{Level: 1466368, LevelPosition: 1466367, Cycle: 357, CyclePosition: 4095, VotingPeriod: 44, VotingPeriodPosition: 20479, ExpectedCommitment: true},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetNonceForLevel(ctx, "main", "head", 1466368)
+			},
+			respFixture:     "fixtures/block/nonce_revealed.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/nonces/1466368",
+			expectedValue:   "a4d0c4f5ab9d4b7ad6a5f28b1f5cab2e3e2d2ed93a93bb8ee6a1f6c5eed3a8b4",
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetNonceForLevel(ctx, "main", "head", 1466368)
+			},
+			respFixture:     "fixtures/block/nonce_committed.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/nonces/1466368",
+			expectedValue:   "nceVSbP3hcecWHY1dYoNUMfyB7gH9S7KbC4hEz3XZK5QCrc5DfFGm",
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetNonceForLevel(ctx, "main", "head", 1466368)
+			},
+			respFixture:     "fixtures/block/nonce_forgotten.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/nonces/1466368",
+			expectedValue:   "",
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetSaplingDiff(ctx, "main", "head", "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", 0, 1)
+			},
+			respFixture:     "fixtures/contracts/sapling_diff.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/contracts/KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43/single_sapling_get_diff",
+			expectedQuery:   "offset_commitment=0&offset_nullifier=1",
+			expectedValue: map[string]interface{}{
+				"root": "fbc2f4300c01f0b7820d00e3347c8da4ee614674376cbc45359daa54f9b5493e",
+				"commitments_and_ciphertexts": []interface{}{
+					[]interface{}{
+						"9e4a6a27c5dbdd2ab3c6f4a1d1bbac0e3b0aa5c0c2b9c3c1b4e2f4f3e2d1c0b1",
+						map[string]interface{}{
+							"cv":          "e8ab7a2f8bf25ac35d3b4e2c7de5a6e2d5a7e26dae79d24fc5eae4e2a7a8b6c3",
+							"epk":         "a7e4b52b7c4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a",
+							"payload_enc": "6f5b0a",
+							"nonce_enc":   "000000000000000000000000000000000000000000000000",
+							"payload_out": "5b3ac4",
+							"nonce_out":   "000000000000000000000000000000000000000000000000",
+						},
+					},
+				},
+				"nullifiers": []interface{}{"d2c9f5b8b1e3a4c6b7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0"},
+			},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetActiveChains(ctx)
+			},
+			respFixture:     "fixtures/monitor/active_chains.chunked",
+			respContentType: "application/json",
+			expectedPath:    "/monitor/active_chains",
+			expectedValue: []*ActiveChain{
+				&ActiveChain{ChainID: "NetXdQprcVkpaWU"},
+				&ActiveChain{ChainID: "NetXm8tYqnMWky1", TestProtocol: "PsBabyM1eUXZseaJdmXFApDSBqj8YBfwELoxZHHW77EMcAbbwAS", ExpirationDate: func() *time.Time { t := timeMustParse("2019-10-20T00:00:00Z"); return &t }()},
+			},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetBlocks(ctx, "main", &BlocksOptions{Length: 2, Head: []string{"BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm"}, MinDate: time.Unix(1568678400, 0)})
+			},
+			respFixture:     "fixtures/chains/blocks.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks",
+			expectedQuery:   "head=BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm&length=2&min_date=1568678400",
+			expectedValue: [][]string{
+				{"BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8"},
+			},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				ch := make(chan []*NetworkPeer, 100)
+				if err := s.MonitorNetworkPeers(ctx, "running", ch); err != nil {
+					return nil, err
+				}
+				close(ch)
+
+				var res [][]*NetworkPeer
+				for b := range ch {
+					res = append(res, b)
+				}
+				return res, nil
+			},
+			respFixture:     "fixtures/network/peers.chunked",
+			respContentType: "application/json",
+			expectedPath:    "/network/peers",
+			expectedQuery:   "filter=running&monitor=",
+			expectedValue:   [][]*NetworkPeer{[]*NetworkPeer{&NetworkPeer{PeerID: "idrnHcGMrFxiYsmxf5Cqd6NhUTUU8X", State: "running", ReachableAt: &NetworkAddress{Addr: "::ffff:45.79.146.133", Port: 39732}, Stat: NetworkStats{TotalBytesSent: 4908012, TotalBytesRecv: 14560268, CurrentInflow: 66, CurrentOutflow: 177}}}, []*NetworkPeer{&NetworkPeer{PeerID: "idrnHcGMrFxiYsmxf5Cqd6NhUTUU8X", State: "disconnected", Stat: NetworkStats{TotalBytesSent: 4908012, TotalBytesRecv: 14560268}}, &NetworkPeer{PeerID: "idsXeq1zboupwXXDdDDiWhBjimeJe3", State: "running", ReachableAt: &NetworkAddress{Addr: "::ffff:104.155.17.238", Port: 9732}}}},
+		},
+		{
+			get:             func(s *Service) (interface{}, error) { return s.GetNetworkSelf(ctx) },
+			respFixture:     "fixtures/network/self.json",
+			respContentType: "application/json",
+			expectedPath:    "/network/self",
+			expectedValue:   "idtTZmNapGXAcfbnPoAcDz6J2xCHZZ",
+		},
+		{
+			get:             func(s *Service) (interface{}, error) { return s.GetNetworkVersions(ctx) },
+			respFixture:     "fixtures/network/versions.json",
+			respContentType: "application/json",
+			expectedPath:    "/network/versions",
+			expectedValue:   []*NetworkVersion{&NetworkVersion{Name: "TEZOS_MAINNET", Major: 0, Minor: 1}},
+		},
+		{
+			get:             func(s *Service) (interface{}, error) { return s.GetWorkersPrevalidators(ctx) },
+			respFixture:     "fixtures/workers/prevalidators.json",
+			respContentType: "application/json",
+			expectedPath:    "/workers/prevalidators",
+			expectedValue:   []*Worker{&Worker{ChainID: "NetXdQprcVkpaWU", Status: WorkerStatus{Phase: "running", Since: timeMustUnmarshalText("2020-06-05T08:41:15Z")}, Information: &WorkerInformation{InstancesNumber: 1, WStatus: &WorkerStatus{Phase: "running", Since: timeMustUnmarshalText("2020-06-05T08:41:15Z")}}}},
+		},
+		{
+			get:             func(s *Service) (interface{}, error) { return s.GetWorkersPrevalidator(ctx, "NetXdQprcVkpaWU") },
+			respFixture:     "fixtures/workers/prevalidator.json",
+			respContentType: "application/json",
+			expectedPath:    "/workers/prevalidators/NetXdQprcVkpaWU",
+			expectedValue:   &WorkerState{Status: WorkerStatus{Phase: "running", Since: timeMustUnmarshalText("2020-06-05T08:41:15Z")}, PendingRequests: []*WorkerRequest{&WorkerRequest{Pushed: timeMustUnmarshalText("2020-06-05T12:01:03Z"), Request: json.RawMessage(`{"request":"flush","block":"BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm"}`)}}, CurrentRequest: &WorkerRequest{Pushed: timeMustUnmarshalText("2020-06-05T12:01:02Z"), Request: json.RawMessage(`{"request":"notify","peer":"idtTZmNapGXAcfbnPoAcDz6J2xCHZZ"}`)}},
+		},
+		{
+			get:             func(s *Service) (interface{}, error) { return s.GetWorkersChainValidators(ctx) },
+			respFixture:     "fixtures/workers/chain_validators.json",
+			respContentType: "application/json",
+			expectedPath:    "/workers/chain_validators",
+			expectedValue:   []*Worker{&Worker{ChainID: "NetXdQprcVkpaWU", Status: WorkerStatus{Phase: "running", Since: timeMustUnmarshalText("2020-06-05T08:41:14Z")}, Information: &WorkerInformation{InstancesNumber: 3, QueueLength: 2}}},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				ch := make(chan *ValidBlock, 100)
+				if err := s.MonitorValidBlocks(ctx, &ValidBlocksOptions{Protocol: []string{"PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt"}, Chain: []string{"main", "test"}}, ch); err != nil {
+					return nil, err
+				}
+				close(ch)
+
+				var res []*ValidBlock
+				for b := range ch {
+					res = append(res, b)
+				}
+				return res, nil
+			},
+			respFixture:     "fixtures/monitor/valid_blocks.chunked",
+			respContentType: "application/json",
+			expectedPath:    "/monitor/valid_blocks",
+			expectedQuery:   "chain=main&chain=test&protocol=PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt",
+			expectedValue: []*ValidBlock{
+				&ValidBlock{ChainID: "NetXZUqeBjDnWde", BlockInfo: BlockInfo{Hash: "BKq199p1Hm1phfJ4DhuRjB6yBSJnDNG8sgMSnja9pXR96T2Hyy1", Timestamp: timeMustUnmarshalText("2019-04-10T22:37:08Z"), OperationsHash: "LLobC6LA4T2STTa3D77YDuDsrw6xEY8DakpkvR9kd7DL9HpvchUtb", Level: 390397, Context: "CoUiJrzomxKms5eELzgpULo2iyf7dJAqW3gEBnFE7WHv3cy9pfVE", Predecessor: "BKihh4Bd3nAypX5bZtYy7xoxQDRbygkoyjB9w171exm2mbXHQWj", Proto: 3, ProtocolData: "000000000003bcf5f72d00320dffeb51c154077ce7dd2af6057f0370485a738345d3cb5c722db6df6ddb9b48c4e7a4282a3b994bca1cc52f6b95c889f23906e1d4e3e20203e171ff924004", ValidationPass: 4, Fitness: []HexBytes{HexBytes{0x0}, HexBytes{0x0, 0x0, 0x0, 0x0, 0x0, 0x5a, 0x12, 0x5f}}}},
+				&ValidBlock{ChainID: "NetXm8tYqnMWky1", BlockInfo: BlockInfo{Hash: "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", Timestamp: timeMustUnmarshalText("2019-04-10T22:37:38Z"), OperationsHash: "LLoZS2LW3rEi7KYU4ouBQtorua37aWWCtpDmv1n2x3xoKi6sVXLWp", Level: 12, Context: "CoUiJrzomxKms5eELzgpULo2iyf7dJAqW3gEBnFE7WHv3cy9pfVE", Predecessor: "BKq199p1Hm1phfJ4DhuRjB6yBSJnDNG8sgMSnja9pXR96T2Hyy1", Proto: 1, ProtocolData: "00000000", ValidationPass: 4, Fitness: []HexBytes{HexBytes{0x0}, HexBytes{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1}}}},
+			},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetBlockProtocols(ctx, "main", BlockLevel(851968))
+			},
+			respFixture:     "fixtures/block/protocols.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/851968/protocols",
+			expectedValue:   &BlockProtocols{Protocol: ProtocolBabylon2, NextProtocol: ProtocolCarthage},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetRawContextBytes(ctx, "main", "head", "/cycle/100", 1)
+			},
+			respFixture:     "fixtures/block/raw_context_cycle.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/raw/bytes/cycle/100",
+			expectedQuery:   "depth=1",
+			expectedValue:   map[string]interface{}{"random_seed": "b6d2d0a7c0d1b36b8ac0c8eec1fd0a6c2d1f5a3c7db1e9c5e3a1b1c0d2e4f6a8", "last_roll": map[string]interface{}{}, "roll_snapshot": "0005"},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetDelegatedContracts(ctx, "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5")
+			},
+			respFixture:     "fixtures/block/delegated_contracts.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/delegated_contracts",
+			expectedValue:   []string{"KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5"},
+		},
 		{
 			get: func(s *Service) (interface{}, error) {
 				ch := make(chan *BootstrappedBlock, 100)
@@ -222,7 +614,7 @@ func TestServiceGetMethods(t *testing.T) {
 			respFixture:     "fixtures/block/pending_operations.json",
 			respContentType: "application/json",
 			expectedPath:    "/chains/main/mempool/pending_operations",
-			expectedValue:   &MempoolOperations{Applied: []*Operation{&Operation{Hash: "opLHEC3xm8qPRP9g44oBpB45RzRVUoMX1NsX75sKKtNvA8pvSm2", Branch: "BMLvebSvhTyZ7GG2vykV8hpGEc8egzcwn9fc3JJKrtCk8FssT9M", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208806}}, Signature: "sigtTW5Y3xQaTKo5vEiqr8zG4YnPv7GbVbUgo7XYw7UZduz9jvdxzFbKUmftKFsFGH1UEZBbxyhyH5DLUUMh5KrQ3MENzUwC"}, &Operation{Hash: "ooSEFHRfArRSjeWhHhcmBa5aL2E3MqsN1HucCm3xiR2gLuzGSYN", Branch: "BMLvebSvhTyZ7GG2vykV8hpGEc8egzcwn9fc3JJKrtCk8FssT9M", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208806}}, Signature: "sigeVFaHCGk9S6P9MhNNyZjHMcfPgYZw5cTwejtbGDEZdp58XKcxVkP3CFCKiPHesiEDqCxvrPGHZUpQLNmmqaSgrmv1ePNZ"}}, Refused: []*OperationWithErrorAlt{}, BranchRefused: []*OperationWithErrorAlt{}, BranchDelayed: []*OperationWithErrorAlt{&OperationWithErrorAlt{Operation: Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", Hash: "oo1Z19oCkTWibLp7mJwFKP3UFVxuf6eV1iNWwJS7gZs8uZbrduS", Branch: "BMTSuKyFBhgmD7e3UDt9jLtjC2ftTUosTGEiiYc61Lu6F3xSkvJ", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208804}}, Signature: "sigZXm4SGNcHwh5qsfjsFYmhSCwtimifq4EPje5rnJxvNDkymC2o3Yv8cJWgug3dDxiQWDexRDeBBu8Pf5qFxA6SckKypiau"}, Error: Errors{&GenericError{Kind: "temporary", ID: "proto.002-PsYLVpVv.operation.wrong_endorsement_predecessor"}}}, &OperationWithErrorAlt{Operation: Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", Hash: "ooCaHemWe76uiBLDUXY2uhbhuiyLG7w7rqUFaJPxr7v56z6DVPS", Branch: "BL1pULCBFDJkqDHmYqK8yrVM3mHQHi72JFg6dT5qJ96ncjDbPpn", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208773}}, Signature: "sigpkWpkY25KDBo7YcaLYx5Q61ypcfFWXjXgvbMG6uFrnStboCxCoCnJbDNri7CGzad35zLUvXCVxu2uj4WBSPgfxsnGKUBn"}, Error: Errors{&GenericError{Kind: "temporary", ID: "proto.002-PsYLVpVv.operation.wrong_endorsement_predecessor"}}}}, Unprocessed: []*OperationAlt{}},
+			expectedValue:   &MempoolOperations{Applied: []*Operation{&Operation{Hash: "opLHEC3xm8qPRP9g44oBpB45RzRVUoMX1NsX75sKKtNvA8pvSm2", Branch: "BMLvebSvhTyZ7GG2vykV8hpGEc8egzcwn9fc3JJKrtCk8FssT9M", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208806}}, Signature: "sigtTW5Y3xQaTKo5vEiqr8zG4YnPv7GbVbUgo7XYw7UZduz9jvdxzFbKUmftKFsFGH1UEZBbxyhyH5DLUUMh5KrQ3MENzUwC"}, &Operation{Hash: "ooSEFHRfArRSjeWhHhcmBa5aL2E3MqsN1HucCm3xiR2gLuzGSYN", Branch: "BMLvebSvhTyZ7GG2vykV8hpGEc8egzcwn9fc3JJKrtCk8FssT9M", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208806}}, Signature: "sigeVFaHCGk9S6P9MhNNyZjHMcfPgYZw5cTwejtbGDEZdp58XKcxVkP3CFCKiPHesiEDqCxvrPGHZUpQLNmmqaSgrmv1ePNZ"}}, Refused: []*OperationWithErrorAlt{}, BranchRefused: []*OperationWithErrorAlt{}, BranchDelayed: []*OperationWithErrorAlt{&OperationWithErrorAlt{Operation: Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", Hash: "oo1Z19oCkTWibLp7mJwFKP3UFVxuf6eV1iNWwJS7gZs8uZbrduS", Branch: "BMTSuKyFBhgmD7e3UDt9jLtjC2ftTUosTGEiiYc61Lu6F3xSkvJ", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208804}}, Signature: "sigZXm4SGNcHwh5qsfjsFYmhSCwtimifq4EPje5rnJxvNDkymC2o3Yv8cJWgug3dDxiQWDexRDeBBu8Pf5qFxA6SckKypiau"}, Error: Errors{&GenericError{Kind: "temporary", ID: "proto.002-PsYLVpVv.operation.wrong_endorsement_predecessor", Details: map[string]interface{}{"expected": "BMLvebSvhTyZ7GG2vykV8hpGEc8egzcwn9fc3JJKrtCk8FssT9M", "provided": "BMTSuKyFBhgmD7e3UDt9jLtjC2ftTUosTGEiiYc61Lu6F3xSkvJ"}}}}, &OperationWithErrorAlt{Operation: Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", Hash: "ooCaHemWe76uiBLDUXY2uhbhuiyLG7w7rqUFaJPxr7v56z6DVPS", Branch: "BL1pULCBFDJkqDHmYqK8yrVM3mHQHi72JFg6dT5qJ96ncjDbPpn", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 208773}}, Signature: "sigpkWpkY25KDBo7YcaLYx5Q61ypcfFWXjXgvbMG6uFrnStboCxCoCnJbDNri7CGzad35zLUvXCVxu2uj4WBSPgfxsnGKUBn"}, Error: Errors{&GenericError{Kind: "temporary", ID: "proto.002-PsYLVpVv.operation.wrong_endorsement_predecessor", Details: map[string]interface{}{"expected": "BMLvebSvhTyZ7GG2vykV8hpGEc8egzcwn9fc3JJKrtCk8FssT9M", "provided": "BL1pULCBFDJkqDHmYqK8yrVM3mHQHi72JFg6dT5qJ96ncjDbPpn"}}}}}, Unprocessed: []*OperationAlt{}},
 		},
 		// Handling 5xx errors from the Tezos node with RPC error information.
 		{
@@ -266,6 +658,18 @@ func TestServiceGetMethods(t *testing.T) {
 			errMsg:          `tezos: error decoding RPC error: invalid character ',' looking for beginning of value`,
 			errType:         (*plainError)(nil),
 		},
+		// Handling 4xx errors from the Tezos node with RPC error information, e.g. a block which doesn't belong to the chain.
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetContractBalance(ctx, "NetXdQprcVkpaWU", "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+			},
+			respStatus:      400,
+			respFixture:     "fixtures/block/inconsistent_chain_error.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/NetXdQprcVkpaWU/blocks/BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/balance",
+			errMsg:          `tezos: kind = "permanent", id = "node.chain_directory.inconsistent_chain"`,
+			errType:         (*rpcError)(nil),
+		},
 		// Handling unexpected response status codes.
 		{
 			get: func(s *Service) (interface{}, error) {
@@ -297,6 +701,37 @@ func TestServiceGetMethods(t *testing.T) {
 			expectedPath:    "/chains/main/blocks/BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm",
 			expectedValue:   &Block{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", ChainID: "NetXZUqeBjDnWde", Hash: "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", Header: RawBlockHeader{Level: 219133, Proto: 1, Predecessor: "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", Timestamp: timeMustUnmarshalText("2018-11-27T17:49:57Z"), ValidationPass: 4, OperationsHash: "LLoZamNeucV8tqPAcqJQYsNEsMwnCuL1xu1kJMiGFCx9MBVCGcWJF", Fitness: []HexBytes{HexBytes{0x0}, HexBytes{0x0, 0x0, 0x0, 0x0, 0x0, 0x5a, 0x12, 0x5f}}, Context: "CoW5zHjWVHfUAbSgzqnZ938eDXG37P9oJVn3Lb3NyQJBheUDvdVf", ProofOfWorkNonce: HexBytes{0x7d, 0x94, 0x95, 0x82, 0xfe, 0x2, 0x48, 0x62}, Signature: "sigktdiZpdykWEjgeTB3N1qFJ5bsh3SxVNB8wc5FAutbJPG7puWQAPrxwL6BZPJVKLRj2uLnCw54Akx4KA48DS5Jg8tthCLY"}, Metadata: BlockHeaderMetadata{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", NextProtocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", TestChainStatus: &NotRunningTestChainStatus{GenericTestChainStatus: GenericTestChainStatus{Status: "not_running"}}, MaxOperationsTTL: 60, MaxOperationDataLength: 16384, MaxBlockHeaderLength: 238, MaxOperationListLength: []*MaxOperationListLength{&MaxOperationListLength{MaxSize: 32768, MaxOp: 32}}, Baker: "tz3gN8NTLNLJg5KRsUU47NHNVHbdhcFXjjaB", Level: BlockHeaderMetadataLevel{Level: 219133, LevelPosition: 219132, Cycle: 106, CyclePosition: 2044, VotingPeriod: 6, VotingPeriodPosition: 22524, ExpectedCommitment: false}, VotingPeriodKind: "proposal", ConsumedGas: &BigInt{}, Deactivated: []string{}, BalanceUpdates: BalanceUpdates{&ContractBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: -512000000}, Contract: "tz3gN8NTLNLJg5KRsUU47NHNVHbdhcFXjjaB"}, &FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 512000000}, Category: "deposits", Delegate: "tz3gN8NTLNLJg5KRsUU47NHNVHbdhcFXjjaB", Level: 106}}}, Operations: [][]*Operation{[]*Operation{&Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", ChainID: "NetXZUqeBjDnWde", Hash: "opEatwYFvwuUM2aEa9cUU1ofMzsi46bYwiUhPLENXpLkjpps4Xq", Branch: "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 219132, Metadata: EndorsementOperationMetadata{BalanceUpdates: BalanceUpdates{&ContractBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "contract", Change: -128000000}, Contract: "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq"}, &FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 128000000}, Category: "deposits", Delegate: "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", Level: 106}, &FreezerBalanceUpdate{GenericBalanceUpdate: GenericBalanceUpdate{Kind: "freezer", Change: 2000000}, Category: "rewards", Delegate: "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", Level: 106}}, Delegate: "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq", Slots: []int{18, 16}}}}, Signature: "sigS3d9wfEFuChEqLetCxf4G8QYAjWL7ND3F8amMPVPDS2RwQqkeKU9hbrEXk7GG7U2aPcWkTA3uTdNzz4gkAb8jSy8hUc51"}}, []*Operation{}, []*Operation{}, []*Operation{}}},
 		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetBlockWithMetadata(ctx, "main", "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", MetadataNever)
+			},
+			respFixture:     "fixtures/chains/block_no_metadata.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm",
+			expectedQuery:   "metadata=never",
+			expectedValue: &Block{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", ChainID: "NetXZUqeBjDnWde", Hash: "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", Header: RawBlockHeader{Level: 219133, Proto: 1, Predecessor: "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", Timestamp: timeMustUnmarshalText("2018-11-27T17:49:57Z"), ValidationPass: 4, OperationsHash: "LLoZamNeucV8tqPAcqJQYsNEsMwnCuL1xu1kJMiGFCx9MBVCGcWJF", Fitness: []HexBytes{HexBytes{0x0}, HexBytes{0x0, 0x0, 0x0, 0x0, 0x0, 0x5a, 0x12, 0x5f}}, Context: "CoW5zHjWVHfUAbSgzqnZ938eDXG37P9oJVn3Lb3NyQJBheUDvdVf", ProofOfWorkNonce: HexBytes{0x7d, 0x94, 0x95, 0x82, 0xfe, 0x2, 0x48, 0x62}, Signature: "sigktdiZpdykWEjgeTB3N1qFJ5bsh3SxVNB8wc5FAutbJPG7puWQAPrxwL6BZPJVKLRj2uLnCw54Akx4KA48DS5Jg8tthCLY"},
+				Operations: [][]*Operation{
+					[]*Operation{&Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", ChainID: "NetXZUqeBjDnWde", Hash: "opEatwYFvwuUM2aEa9cUU1ofMzsi46bYwiUhPLENXpLkjpps4Xq", Branch: "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 219132}}, Signature: "sigS3d9wfEFuChEqLetCxf4G8QYAjWL7ND3F8amMPVPDS2RwQqkeKU9hbrEXk7GG7U2aPcWkTA3uTdNzz4gkAb8jSy8hUc51"}},
+					[]*Operation{},
+					[]*Operation{},
+					[]*Operation{&Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", ChainID: "NetXZUqeBjDnWde", Hash: "ooSEFHRfArRSjeWhHhcmBa5aL2E3MqsN1HucCm3xiR2gLuzGSYN", Branch: "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", Contents: OperationElements{&TransactionOperationElem{GenericOperationElem: GenericOperationElem{Kind: "transaction"}, Source: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", Fee: bigIntMust("1420"), Counter: bigIntMust("10"), GasLimit: bigIntMust("10307"), StorageLimit: bigIntMust("0"), Amount: bigIntMust("1000000"), Destination: "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"}}, Signature: "sigeVFaHCGk9S6P9MhNNyZjHMcfPgYZw5cTwejtbGDEZdp58XKcxVkP3CFCKiPHesiEDqCxvrPGHZUpQLNmmqaSgrmv1ePNZ"}},
+				},
+			},
+		},
+		{
+			get: func(s *Service) (interface{}, error) {
+				return s.GetBlockOperations(ctx, "main", BlockHash("BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm"))
+			},
+			respFixture:     "fixtures/block/operations.json",
+			respContentType: "application/json",
+			expectedPath:    "/chains/main/blocks/BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm/operations",
+			expectedValue: [][]*Operation{
+				[]*Operation{&Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", ChainID: "NetXZUqeBjDnWde", Hash: "opEatwYFvwuUM2aEa9cUU1ofMzsi46bYwiUhPLENXpLkjpps4Xq", Branch: "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", Contents: OperationElements{&EndorsementOperationElem{GenericOperationElem: GenericOperationElem{Kind: "endorsement"}, Level: 219132}}, Signature: "sigS3d9wfEFuChEqLetCxf4G8QYAjWL7ND3F8amMPVPDS2RwQqkeKU9hbrEXk7GG7U2aPcWkTA3uTdNzz4gkAb8jSy8hUc51"}},
+				[]*Operation{},
+				[]*Operation{},
+				[]*Operation{&Operation{Protocol: "PsYLVpVvgbLhAhoqAkMFUo6gudkJ9weNXhUYCiLDzcUpFpkk8Wt", ChainID: "NetXZUqeBjDnWde", Hash: "ooSEFHRfArRSjeWhHhcmBa5aL2E3MqsN1HucCm3xiR2gLuzGSYN", Branch: "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", Contents: OperationElements{&TransactionOperationElem{GenericOperationElem: GenericOperationElem{Kind: "transaction"}, Source: "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", Fee: bigIntMust("1420"), Counter: bigIntMust("10"), GasLimit: bigIntMust("10307"), StorageLimit: bigIntMust("0"), Amount: bigIntMust("1000000"), Destination: "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"}}, Signature: "sigeVFaHCGk9S6P9MhNNyZjHMcfPgYZw5cTwejtbGDEZdp58XKcxVkP3CFCKiPHesiEDqCxvrPGHZUpQLNmmqaSgrmv1ePNZ"}},
+			},
+		},
 		{
 			get: func(s *Service) (interface{}, error) {
 				ch := make(chan *BlockInfo, 100)
@@ -458,3 +893,920 @@ func TestServiceGetMethods(t *testing.T) {
 		srv.Close()
 	}
 }
+
+func TestBigInt(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected int64
+	}{
+		{src: `"123"`, expected: 123},
+		{src: `123`, expected: 123},
+		{src: `"-9007199254740993"`, expected: -9007199254740993},
+		{src: `9007199254740993`, expected: 9007199254740993},
+	}
+
+	for _, test := range tests {
+		var v BigInt
+		require.NoError(t, json.Unmarshal([]byte(test.src), &v))
+
+		i, ok := v.Int64()
+		require.True(t, ok)
+		require.Equal(t, test.expected, i)
+
+		buf, err := json.Marshal(&v)
+		require.NoError(t, err)
+		require.Equal(t, `"`+strconv.FormatInt(test.expected, 10)+`"`, string(buf))
+	}
+
+	var v BigInt
+	require.Error(t, json.Unmarshal([]byte(`"12a"`), &v))
+
+	var st struct {
+		Fee *BigInt `json:"fee"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"fee":null}`), &st))
+	require.Nil(t, st.Fee)
+
+	x := bigIntMust("18446744073709551615")
+	_, ok := x.Int64()
+	require.False(t, ok)
+
+	sum := new(BigInt).Add(x, bigIntMust("1"))
+	require.Equal(t, "18446744073709551616", sum.String())
+	require.Equal(t, 1, sum.Cmp(x))
+	require.Equal(t, -1, x.Cmp(sum))
+	require.Equal(t, 0, x.Cmp(bigIntMust("18446744073709551615")))
+}
+
+func TestGetCycleHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/helpers/current_level":
+			fmt.Fprint(w, `{"level":21,"level_position":20,"cycle":4,"cycle_position":0}`)
+			return
+		case "/chains/main/blocks/head/helpers/levels_in_current_cycle":
+			// blocks_per_cycle has changed from 2 to 4 at level 5
+			require.Equal(t, "-2", r.URL.Query().Get("offset"))
+			fmt.Fprint(w, `{"first":9,"last":12}`)
+			return
+		}
+
+		var level int
+		_, err := fmt.Sscanf(r.URL.Path, "/chains/main/blocks/%d/header", &level)
+		require.NoError(t, err)
+
+		fmt.Fprintf(w, `{"protocol":"PsBabyM1eUXZseaJdmXFApDSBqj8YBfwELoxZHHW77EMcAbbwAS","chain_id":"NetXdQprcVkpaWU","hash":"BLock%d","level":%d,"priority":%d}`, level, level, level%2)
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	headers, err := s.GetCycleHeaders(context.Background(), "main", 2, 3)
+	require.NoError(t, err)
+	require.Len(t, headers, 4)
+
+	for i, h := range headers {
+		level := 9 + i
+		require.Equal(t, level, h.Level)
+		require.Equal(t, level%2, h.Priority)
+	}
+}
+
+func TestDiffMempool(t *testing.T) {
+	op1 := &Operation{Hash: "opLHEC3xm8qPRP9g44oBpB45RzRVUoMX1NsX75sKKtNvA8pvSm2"}
+	op2 := &Operation{Hash: "ooSEFHRfArRSjeWhHhcmBa5aL2E3MqsN1HucCm3xiR2gLuzGSYN"}
+	op3 := &Operation{Hash: "oo1Z19oCkTWibLp7mJwFKP3UFVxuf6eV1iNWwJS7gZs8uZbrduS"}
+
+	prev := &MempoolOperations{Applied: []*Operation{op1, op2}}
+	next := &MempoolOperations{Applied: []*Operation{op2, op3}}
+
+	added, removed := DiffMempool(prev, next)
+	require.Equal(t, []*Operation{op3}, added)
+	require.Equal(t, []*Operation{op1}, removed)
+
+	added, removed = DiffMempool(nil, next)
+	require.Equal(t, []*Operation{op2, op3}, added)
+	require.Empty(t, removed)
+}
+
+func TestGetContractManagerBabylon(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/manager":
+			w.WriteHeader(http.StatusNotFound)
+		case "/chains/main/blocks/head/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/manager_key":
+			buf, err := ioutil.ReadFile("fixtures/contracts/manager_key.json")
+			require.NoError(t, err)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(buf)
+		case "/chains/main/blocks/head/context/contracts/tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN/manager":
+			w.WriteHeader(http.StatusNotFound)
+		case "/chains/main/blocks/head/context/contracts/tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN/manager_key":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("null"))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	manager, err := s.GetContractManager(context.Background(), "main", "head", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.NoError(t, err)
+	require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", manager)
+
+	// Unrevealed
+	manager, err = s.GetContractManager(context.Background(), "main", "head", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN")
+	require.NoError(t, err)
+	require.Empty(t, manager)
+}
+
+func TestBalanceTimeSeriesSparse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chains/main/blocks/100/context/contracts/KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv/balance":
+			// Not originated yet
+			w.WriteHeader(http.StatusNotFound)
+		case "/chains/main/blocks/200/context/contracts/KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv/balance":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`"0"`))
+		case "/chains/main/blocks/300/context/contracts/KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv/balance":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`"1000000"`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	res, err := s.BalanceTimeSeriesSparse(context.Background(), "main", "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv", []string{"100", "200", "300"}, 2)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	v, ok := res["100"]
+	require.True(t, ok)
+	require.Nil(t, v)
+	require.Equal(t, bigIntMust("0"), res["200"])
+	require.Equal(t, bigIntMust("1000000"), res["300"])
+}
+
+func TestInjectWithCounterRetry(t *testing.T) {
+	var counterReqs, injectReqs int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/counter":
+			counterReqs++
+			// Another operation of the same source got included in between
+			fmt.Fprintf(w, `"%d"`, 9+counterReqs)
+
+		case "/injection/operation":
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "chain=main", r.URL.RawQuery)
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			injectReqs++
+			if injectReqs == 1 {
+				require.JSONEq(t, `"0b"`, string(body))
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`[{"kind":"temporary","id":"proto.005-PsBabyM1.contract.counter_in_the_past","contract":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx","expected":"12","found":"11"}]`))
+				return
+			}
+			require.JSONEq(t, `"0c"`, string(body))
+			w.Write([]byte(`"ooSEFHRfArRSjeWhHhcmBa5aL2E3MqsN1HucCm3xiR2gLuzGSYN"`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	var counters []int64
+	hash, err := s.InjectWithCounterRetry(context.Background(), "main", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", func(ctx context.Context, counter *big.Int) (HexBytes, error) {
+		counters = append(counters, counter.Int64())
+		// Just the counter instead of the forged operation
+		return HexBytes{byte(counter.Int64())}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ooSEFHRfArRSjeWhHhcmBa5aL2E3MqsN1HucCm3xiR2gLuzGSYN", hash)
+	require.Equal(t, []int64{11, 12}, counters)
+	require.Equal(t, 2, injectReqs)
+}
+
+func TestGetBalanceAtCycles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/helpers/current_level":
+			fmt.Fprint(w, `{"level":40961,"level_position":40960,"cycle":10,"cycle_position":0}`)
+			return
+		case "/chains/main/blocks/head/helpers/levels_in_current_cycle":
+			offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+			require.NoError(t, err)
+			cycle := 10 + offset
+			fmt.Fprintf(w, `{"first":%d,"last":%d}`, cycle*4096+1, (cycle+1)*4096)
+			return
+		case "/chains/main/blocks/28673/context/selected_snapshot":
+			require.Equal(t, "7", r.URL.Query().Get("cycle"))
+			fmt.Fprint(w, `3`)
+			return
+		case "/chains/main/blocks/head/context/selected_snapshot":
+			// Future cycle
+			require.Equal(t, "12", r.URL.Query().Get("cycle"))
+			fmt.Fprint(w, `9`)
+			return
+		case "/chains/main/blocks/32769/context/selected_snapshot":
+			// Older nodes
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/chains/main/blocks/32769/context/raw/json/cycle/8/roll_snapshot":
+			fmt.Fprint(w, `15`)
+			return
+		}
+
+		var level int
+		_, err := fmt.Sscanf(r.URL.Path, "/chains/main/blocks/%d/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/balance", &level)
+		require.NoError(t, err)
+		fmt.Fprintf(w, `"%d"`, level*1000)
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	constants := &Constants{PreservedCycles: 5, BlocksPerCycle: 4096, BlocksPerRollSnapshot: 256}
+	res, err := s.GetBalanceAtCycles(context.Background(), "main", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", []int32{7, 8, 12}, constants)
+	require.NoError(t, err)
+	require.Equal(t, map[int32]*BigInt{
+		7:  bigIntMust("1024000"),  // 4th snapshot of cycle 0
+		8:  bigIntMust("8192000"),  // last snapshot of cycle 1
+		12: bigIntMust("23040000"), // 10th snapshot of cycle 5
+	}, res)
+
+	_, err = s.GetBalanceAtCycles(context.Background(), "main", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", []int32{6}, constants)
+	require.EqualError(t, err, "tezos: cycle 6 has no roll snapshot")
+}
+
+func TestStreamOperations(t *testing.T) {
+	blocks := map[string]string{
+		"/chains/main/blocks/100/operations": `[
+			[{"hash": "op1", "contents": [{"kind": "endorsement", "level": 99}]}],
+			[],
+			[],
+			[{"hash": "op2", "contents": [{"kind": "transaction", "amount": "1"}]}]
+		]`,
+		"/chains/main/blocks/101/operations": `[
+			[],
+			[],
+			[],
+			[
+				{"hash": "op3", "contents": [{"kind": "delegation"}]},
+				{"hash": "op4", "contents": [{"kind": "reveal"}, {"kind": "transaction", "amount": "2"}]}
+			]
+		]`,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := blocks[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, b)
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	ch := make(chan *Operation, 100)
+	require.NoError(t, s.StreamOperations(context.Background(), "main", 100, 101, "transaction", ch))
+	close(ch)
+
+	var hashes []string
+	for op := range ch {
+		hashes = append(hashes, op.Hash)
+	}
+	require.Equal(t, []string{"op2", "op4"}, hashes)
+}
+
+func TestGetContractDelegate(t *testing.T) {
+	delegate := "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"
+
+	cases := []struct {
+		status   int
+		body     string
+		expected *string
+	}{
+		{status: http.StatusOK, body: `"tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN"`, expected: &delegate},
+		{status: http.StatusNotFound, body: `[]`},
+		{status: http.StatusOK, body: `null`},
+	}
+
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/chains/main/blocks/head/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/delegate", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(c.status)
+			fmt.Fprint(w, c.body)
+		}))
+
+		cl, err := NewRPCClient(srv.URL)
+		require.NoError(t, err)
+		s := &Service{Client: cl}
+
+		res, err := s.GetContractDelegate(context.Background(), "main", "head", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+		require.NoError(t, err, c.body)
+		require.Equal(t, c.expected, res, c.body)
+
+		str, err := s.GetContractDelegateString(context.Background(), "main", "head", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+		require.NoError(t, err, c.body)
+		if c.expected != nil {
+			require.Equal(t, *c.expected, str)
+		} else {
+			require.Empty(t, str)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestGetBlockByLevel(t *testing.T) {
+	blockData, err := ioutil.ReadFile("fixtures/chains/block.json")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/219133":
+			w.Write(blockData)
+		case "/chains/main/blocks/head/header":
+			fmt.Fprint(w, `{"hash": "BLnoArJNPCyYFK2z3Mnomi36Jo3FwrjriJ6hvzgTJGYYDKEkDXm", "level": 219133}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	block, err := s.GetBlockByLevel(context.Background(), "main", 219133)
+	require.NoError(t, err)
+	require.Equal(t, 219133, block.Header.Level)
+
+	_, err = s.GetBlockByLevel(context.Background(), "main", 219134)
+	require.EqualError(t, err, "tezos: level 219134 is above the current head level 219133")
+}
+
+func TestFindOperation(t *testing.T) {
+	blocks := map[string]string{
+		"/chains/main/blocks/100": `{"hash": "BL100", "header": {"level": 100}, "operations": [[{"hash": "op1", "contents": []}], [], [], []]}`,
+		"/chains/main/blocks/101": `{"hash": "BL101", "header": {"level": 101}, "operations": [[], [], [], [{"hash": "op2", "contents": []}, {"hash": "op3", "contents": []}]]}`,
+		"/chains/test/blocks/100": `{"hash": "BT100", "header": {"level": 100}, "operations": [[{"hash": "op1", "contents": []}], [], [], []]}`,
+	}
+
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		b, ok := blocks[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, b)
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	blockHash, err := s.FindOperation(context.Background(), "main", "op2", 100, 101)
+	require.NoError(t, err)
+	require.Equal(t, "BL101", blockHash)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// Both blocks are remembered
+	for op, expected := range map[string]string{"op1": "BL100", "op2": "BL101", "op3": "BL101"} {
+		blockHash, err = s.FindOperation(context.Background(), "main", op, 100, 101)
+		require.NoError(t, err)
+		require.Equal(t, expected, blockHash)
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	blockHash, err = s.FindOperation(context.Background(), "main", "op4", 100, 100)
+	require.NoError(t, err)
+	require.Empty(t, blockHash)
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// Remembered block is out of the range
+	blockHash, err = s.FindOperation(context.Background(), "main", "op1", 101, 101)
+	require.NoError(t, err)
+	require.Empty(t, blockHash)
+	require.Equal(t, int32(4), atomic.LoadInt32(&requests))
+
+	// Chains are kept apart
+	blockHash, err = s.FindOperation(context.Background(), "test", "op1", 100, 100)
+	require.NoError(t, err)
+	require.Equal(t, "BT100", blockHash)
+	require.Equal(t, int32(5), atomic.LoadInt32(&requests))
+
+	// Reorganization at level 101
+	blocks["/chains/main/blocks/101"] = `{"hash": "BL101b", "header": {"level": 101}, "operations": [[], [], [], [{"hash": "op5", "contents": []}]]}`
+	blockHash, err = s.FindOperation(context.Background(), "main", "op5", 101, 101)
+	require.NoError(t, err)
+	require.Equal(t, "BL101b", blockHash)
+	require.Equal(t, int32(6), atomic.LoadInt32(&requests))
+
+	blockHash, err = s.FindOperation(context.Background(), "main", "op2", 100, 101)
+	require.NoError(t, err)
+	require.Empty(t, blockHash)
+	require.Equal(t, int32(8), atomic.LoadInt32(&requests))
+
+	blockHash, err = s.FindOperation(context.Background(), "main", "op1", 100, 101)
+	require.NoError(t, err)
+	require.Equal(t, "BL100", blockHash)
+	require.Equal(t, int32(8), atomic.LoadInt32(&requests))
+
+	s.InvalidateOperationCache("main", 100)
+	blockHash, err = s.FindOperation(context.Background(), "main", "op1", 100, 101)
+	require.NoError(t, err)
+	require.Equal(t, "BL100", blockHash)
+	require.Equal(t, int32(9), atomic.LoadInt32(&requests))
+
+	blockHash, err = s.FindOperation(context.Background(), "test", "op1", 100, 100)
+	require.NoError(t, err)
+	require.Equal(t, "BT100", blockHash)
+	require.Equal(t, int32(9), atomic.LoadInt32(&requests))
+}
+
+func TestPreapplyOperations(t *testing.T) {
+	var batches [][]map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/chains/main/blocks/head/helpers/preapply/operations", r.URL.Path)
+
+		var groups []map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&groups))
+		batches = append(batches, groups)
+
+		w.Header().Set("Content-Type", "application/json")
+		// The groups are applied in sequence on top of the account counter
+		counter := 10
+		for _, g := range groups {
+			el := g["contents"].([]interface{})[0].(map[string]interface{})
+			_, hasMetadata := el["metadata"]
+			require.False(t, hasMetadata)
+
+			if el["counter"] != strconv.Itoa(counter+1) {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, `[{"kind": "temporary", "id": "proto.005-PsBabyM1.contract.counter_in_the_past", "contract": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "expected": "%d", "found": "%s"}]`, counter+1, el["counter"])
+				return
+			}
+			counter++
+		}
+
+		res := make([]map[string]interface{}, len(groups))
+		for i, g := range groups {
+			el := g["contents"].([]interface{})[0].(map[string]interface{})
+			el["metadata"] = map[string]interface{}{
+				"balance_updates":  []interface{}{},
+				"operation_result": map[string]interface{}{"status": "applied"},
+			}
+			res[i] = map[string]interface{}{"contents": []interface{}{el}, "signature": g["signature"]}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	tx := func(counter string) *Operation {
+		return &Operation{
+			Protocol: "PsBabyM1eUXZseaJdmXFApDSBqj8YBfwELoxZHHW77EMcAbbwAS",
+			Branch:   "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8",
+			Contents: OperationElements{
+				&TransactionOperationElem{
+					GenericOperationElem: GenericOperationElem{Kind: "transaction"},
+					Source:               "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+					Fee:                  bigIntMust("1420"),
+					Counter:              bigIntMust(counter),
+					GasLimit:             bigIntMust("10307"),
+					StorageLimit:         bigIntMust("0"),
+					Amount:               bigIntMust("1000000"),
+					Destination:          "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN",
+				},
+			},
+			Signature: "sigeVFaHCGk9S6P9MhNNyZjHMcfPgYZw5cTwejtbGDEZdp58XKcxVkP3CFCKiPHesiEDqCxvrPGHZUpQLNmmqaSgrmv1ePNZ",
+		}
+	}
+
+	ops := []*Operation{tx("11"), tx("12"), tx("12"), tx("13")}
+	res, err := s.PreapplyOperations(context.Background(), "main", "head", ops)
+	require.NoError(t, err)
+	require.Len(t, res, 4)
+	// The batch is rejected as a whole and then each group is retried after the valid ones preceding it
+	require.Len(t, batches, 5)
+	counters := func(batch []map[string]interface{}) []interface{} {
+		var res []interface{}
+		for _, g := range batch {
+			res = append(res, g["contents"].([]interface{})[0].(map[string]interface{})["counter"])
+		}
+		return res
+	}
+	require.Equal(t, []interface{}{"11"}, counters(batches[1]))
+	require.Equal(t, []interface{}{"11", "12"}, counters(batches[2]))
+	require.Equal(t, []interface{}{"11", "12", "12"}, counters(batches[3]))
+	require.Equal(t, []interface{}{"11", "12", "13"}, counters(batches[4]))
+
+	for _, i := range []int{0, 1, 3} {
+		require.NoError(t, res[i].Error)
+		el, ok := res[i].Operation.Contents[0].(*TransactionOperationElem)
+		require.True(t, ok)
+		require.Equal(t, "applied", el.Metadata.OperationResult.Status)
+		require.Equal(t, ops[i].Contents[0].(*TransactionOperationElem).Counter, el.Counter)
+	}
+
+	require.Nil(t, res[2].Operation)
+	require.Error(t, res[2].Error)
+	require.True(t, rpcErrors(res[2].Error).IsCounterError())
+}
+
+func TestSelectBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/header":
+			w.Write([]byte(`{"hash":"BLockHead","level":1000}`))
+		case "/chains/main/blocks/BLockHead/metadata":
+			w.Write([]byte(`{"max_operations_ttl":60}`))
+		case "/chains/main/blocks/BLockHead~5/header":
+			w.Write([]byte(`{"hash":"BLockHeadMinus5","level":995}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	branch, err := s.SelectBranch(context.Background(), "main", 5)
+	require.NoError(t, err)
+	require.Equal(t, "BLockHeadMinus5", branch)
+
+	branch, err = s.SelectBranch(context.Background(), "main", 0)
+	require.NoError(t, err)
+	require.Equal(t, "BLockHead", branch)
+
+	_, err = s.SelectBranch(context.Background(), "main", 60)
+	require.Error(t, err)
+
+	_, err = s.SelectBranch(context.Background(), "main", -1)
+	require.Error(t, err)
+}
+
+func TestRunView(t *testing.T) {
+	resp, err := ioutil.ReadFile("fixtures/scripts/run_view.json")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/chain_id":
+			w.Write([]byte(`"NetXdQprcVkpaWU"`))
+		case "/chains/main/blocks/head/helpers/scripts/run_view":
+			require.Equal(t, http.MethodPost, r.Method)
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"contract":"KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH","entrypoint":"getBalance","input":{"string":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"},"chain_id":"NetXdQprcVkpaWU","unparsing_mode":"Readable"}`, string(body))
+			w.Write(resp)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	res, err := s.RunView(context.Background(), "main", "head", "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH", "getBalance", map[string]interface{}{"string": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"int": "1000000"}, res)
+}
+
+func TestEstimateFees(t *testing.T) {
+	constants, err := ioutil.ReadFile("fixtures/block/constants.json")
+	require.NoError(t, err)
+	simulated, err := ioutil.ReadFile("fixtures/operations/run_operation.json")
+	require.NoError(t, err)
+
+	expectedGasLimit := "800000"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/context/constants":
+			w.Write(constants)
+		case "/chains/main/chain_id":
+			w.Write([]byte(`"NetXdQprcVkpaWU"`))
+		case "/chains/main/blocks/head/helpers/scripts/run_operation":
+			require.Equal(t, http.MethodPost, r.Method)
+			var body struct {
+				Operation struct {
+					Branch    string                   `json:"branch"`
+					Contents  []map[string]interface{} `json:"contents"`
+					Signature string                   `json:"signature"`
+				} `json:"operation"`
+				ChainID string `json:"chain_id"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "NetXdQprcVkpaWU", body.ChainID)
+			require.Equal(t, "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", body.Operation.Branch)
+			require.NotEmpty(t, body.Operation.Signature)
+			require.Len(t, body.Operation.Contents, 2)
+			for _, el := range body.Operation.Contents {
+				require.Equal(t, expectedGasLimit, el["gas_limit"])
+				require.Equal(t, "60000", el["storage_limit"])
+				require.NotContains(t, el, "metadata")
+			}
+			w.Write(simulated)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	op := NewOperationBuilder(bigIntMust("30")).
+		AddReveal("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "edpkuBknW28nW72KG6RoHtYW7p12T6GKc7nAbwYX5m8Wd9sDVC9yav").
+		AddTransaction("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", bigIntMust("1000000")).
+		Build()
+
+	estimated, err := s.EstimateFees(context.Background(), "main", "head", op, "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", nil)
+	require.NoError(t, err)
+	require.Len(t, estimated, 2)
+
+	// The original contents are left untouched
+	require.Equal(t, "0", op[0].(*RevealOperationElem).GasLimit.String())
+
+	reveal := estimated[0].(*RevealOperationElem)
+	require.Equal(t, "10100", reveal.GasLimit.String())
+	require.Equal(t, "0", reveal.StorageLimit.String())
+
+	tx := estimated[1].(*TransactionOperationElem)
+	require.Equal(t, "35307", tx.GasLimit.String())   // 25000 + 10207 internal + 100
+	require.Equal(t, "344", tx.StorageLimit.String()) // 67 + 257 allocation of the internal destination + 20
+
+	for _, el := range estimated {
+		var buf bytes.Buffer
+		require.NoError(t, forgeOperationElem(&buf, el))
+		var fee, gasLimit *BigInt
+		switch el := el.(type) {
+		case *RevealOperationElem:
+			fee, gasLimit = el.Fee, el.GasLimit
+		case *TransactionOperationElem:
+			fee, gasLimit = el.Fee, el.GasLimit
+		}
+		require.Equal(t, MinimalFee(buf.Len()+48, &gasLimit.Int, nil), &fee.Int)
+	}
+
+	// Custom margins
+	config := &FeeConfig{GasSafetyMargin: 1000, StorageSafetyMargin: 100, FeeBufferMutez: 500}
+	estimated, err = s.EstimateFees(context.Background(), "main", "head", op, "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", config)
+	require.NoError(t, err)
+	tx = estimated[1].(*TransactionOperationElem)
+	require.Equal(t, "36207", tx.GasLimit.String())
+	require.Equal(t, "424", tx.StorageLimit.String())
+	var buf bytes.Buffer
+	require.NoError(t, forgeOperationElem(&buf, tx))
+	require.Equal(t, new(big.Int).Add(MinimalFee(buf.Len()+48, &tx.GasLimit.Int, nil), big.NewInt(500)), &tx.Fee.Int)
+
+	// The group is simulated within the block gas limit
+	var tmp map[string]interface{}
+	require.NoError(t, json.Unmarshal(constants, &tmp))
+	tmp["hard_gas_limit_per_block"] = "1000000"
+	constants, err = json.Marshal(tmp)
+	require.NoError(t, err)
+	expectedGasLimit = "500000"
+
+	_, err = s.EstimateFees(context.Background(), "main", "head", op, "BLNWdEensT9MFq8pkDwjHfGVFsV1reYUhVcMAVzq3LCMS1WdKZ8", nil)
+	require.NoError(t, err)
+}
+
+func TestIsBootstrapped(t *testing.T) {
+	cases := []struct {
+		fixture      string
+		bootstrapped bool
+		synced       bool
+	}{
+		{fixture: "fixtures/chains/is_bootstrapped_bool.json", bootstrapped: true, synced: true},
+		{fixture: "fixtures/chains/is_bootstrapped.json", bootstrapped: true, synced: false},
+	}
+
+	for _, c := range cases {
+		data, err := ioutil.ReadFile(c.fixture)
+		require.NoError(t, err)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/chains/main/is_bootstrapped", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(data)
+		}))
+
+		cl, err := NewRPCClient(srv.URL)
+		require.NoError(t, err)
+		s := &Service{Client: cl}
+
+		bootstrapped, synced, err := s.IsBootstrapped(context.Background(), "main")
+		srv.Close()
+		require.NoError(t, err, c.fixture)
+		require.Equal(t, c.bootstrapped, bootstrapped, c.fixture)
+		require.Equal(t, c.synced, synced, c.fixture)
+	}
+}
+
+func TestNetworkPeerString(t *testing.T) {
+	p := &NetworkPeer{PeerID: "idrnHcGMrFxiYsmxf5Cqd6NhUTUU8X", State: "running", ReachableAt: &NetworkAddress{Addr: "::ffff:45.79.146.133", Port: 39732}}
+	require.Equal(t, "idrnHcGMrFxiYsmxf5Cqd6NhUTUU8X (running, [::ffff:45.79.146.133]:39732)", p.String())
+
+	p = &NetworkPeer{PeerID: "idsXeq1zboupwXXDdDDiWhBjimeJe3", State: "disconnected"}
+	require.Equal(t, "idsXeq1zboupwXXDdDDiWhBjimeJe3 (disconnected)", p.String())
+}
+
+func TestConnectToNetworkPoint(t *testing.T) {
+	var query []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/network/points/80.214.69.170:9732", r.URL.Path)
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{}`, string(body))
+		query = append(query, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	// Zero timeout leaves the node default in effect
+	require.NoError(t, s.ConnectToNetworkPoint(context.Background(), "80.214.69.170:9732", 5*time.Second))
+	require.NoError(t, s.ConnectToNetworkPoint(context.Background(), "80.214.69.170:9732", 0))
+	require.Equal(t, []string{"timeout=5.000000", ""}, query)
+}
+
+func TestRawRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/context/raw/json/cycle/100":
+			require.Equal(t, http.MethodGet, r.Method)
+			require.Equal(t, "depth=1", r.URL.RawQuery)
+			w.Write([]byte(`{"last_roll":[],"nonces":[]}`))
+		case "/chains/main/blocks/head/helpers/scripts/pack_data":
+			require.Equal(t, http.MethodPost, r.Method)
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"data":{"int":"1"},"type":{"prim":"nat"}}`, string(body))
+			w.Write([]byte(`{"packed":"050001","gas":"unaccounted"}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	res, err := s.GetRaw(context.Background(), "/chains/main/blocks/head/context/raw/json/cycle/100", url.Values{"depth": []string{"1"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"last_roll":[],"nonces":[]}`, string(res))
+
+	body := map[string]interface{}{
+		"data": map[string]interface{}{"int": "1"},
+		"type": map[string]interface{}{"prim": "nat"},
+	}
+	res, err = s.PostRaw(context.Background(), "/chains/main/blocks/head/helpers/scripts/pack_data", body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"packed":"050001","gas":"unaccounted"}`, string(res))
+}
+
+func TestStreamDelegatedContracts(t *testing.T) {
+	const total = 100000
+	first := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/chains/main/blocks/head/context/delegates/tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5/delegated_contracts", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `["contract0"`)
+		w.(http.Flusher).Flush()
+
+		// The rest of the array is only sent after the client has consumed the first element
+		select {
+		case <-first:
+		case <-time.After(5 * time.Second):
+			t.Error("the first element wasn't received before the end of the response")
+		}
+
+		for i := 1; i < total; i++ {
+			fmt.Fprintf(w, `,"contract%d"`, i)
+		}
+		fmt.Fprintf(w, "]")
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	ch := make(chan string)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.StreamDelegatedContracts(context.Background(), "main", "head", "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5", ch)
+		close(ch)
+	}()
+
+	require.Equal(t, "contract0", <-ch)
+	close(first)
+
+	n := 1
+	for v := range ch {
+		require.Equal(t, "contract"+strconv.Itoa(n), v)
+		n++
+	}
+	require.NoError(t, <-errCh)
+	require.Equal(t, total, n)
+}
+
+func TestGetContractFullBalance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/balance":
+			w.Write([]byte(`"4000000"`))
+		case "/chains/main/blocks/head/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/frozen_bonds":
+			w.Write([]byte(`"10000000000"`))
+		case "/chains/main/blocks/1000/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/balance":
+			w.Write([]byte(`"3000000"`))
+		default:
+			// Old protocols don't have frozen bonds
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	balance, err := s.GetContractFullBalance(context.Background(), "main", "head", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.NoError(t, err)
+	require.Equal(t, &FullBalance{Balance: big.NewInt(4000000), FrozenBonds: big.NewInt(10000000000)}, balance)
+
+	balance, err = s.GetContractFullBalance(context.Background(), "main", "1000", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.NoError(t, err)
+	require.Equal(t, &FullBalance{Balance: big.NewInt(3000000)}, balance)
+
+	_, err = s.GetContractFullBalance(context.Background(), "main", "1000", "tz1gjaF81ZRRvdzjobyfVNsAeSC6PScjfQwN")
+	require.Error(t, err)
+}
@@ -0,0 +1,445 @@
+package tezos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContractScript is a contract's code along with the current storage
+type ContractScript struct {
+	Code    *Micheline `json:"code" yaml:"code"`
+	Storage *Micheline `json:"storage" yaml:"storage"`
+}
+
+// StorageType returns the storage type from the code
+func (cs *ContractScript) StorageType() (*Micheline, error) {
+	if cs.Code != nil && cs.Code.Kind == MichelineSeq {
+		for _, section := range cs.Code.Seq {
+			if section.Kind == MichelinePrim && section.Prim == "storage" && len(section.Args) == 1 {
+				return section.Args[0], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("tezos: storage type not found in the contract code")
+}
+
+// GetContractScript returns the code and the storage of a contract
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-contracts-contract-id-script
+func (s *Service) GetContractScript(ctx context.Context, chainID, blockID, contractID string) (*ContractScript, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/script", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var script ContractScript
+	if err := s.Client.Do(req, &script); err != nil {
+		return nil, err
+	}
+
+	return &script, nil
+}
+
+// UnparsingMode controls the representation of Michelson data returned by the node
+type UnparsingMode string
+
+// Unparsing modes
+const (
+	UnparsingReadable        UnparsingMode = "Readable"         // e.g. addresses as tz1... strings and timestamps as RFC 3339 dates
+	UnparsingOptimized       UnparsingMode = "Optimized"        // e.g. addresses as bytes and timestamps as integers
+	UnparsingOptimizedLegacy UnparsingMode = "Optimized_legacy" // Optimized without the comb pairs notation
+)
+
+// NormalizeOptions holds parameters of the normalized storage and script endpoints
+type NormalizeOptions struct {
+	UnparsingMode UnparsingMode
+	// NormalizeTypes replaces comb pair types with their nested right combs form. Applies to scripts only.
+	NormalizeTypes bool
+}
+
+// GetContractStorage returns the storage of a contract as stored by the node
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-block-id-context-contracts-contract-id-storage
+func (s *Service) GetContractStorage(ctx context.Context, chainID, blockID, contractID string) (*Micheline, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/storage", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var storage Micheline
+	if err := s.Client.Do(req, &storage); err != nil {
+		return nil, err
+	}
+
+	return &storage, nil
+}
+
+// GetContractStorageWithOptions is like GetContractStorage but unparses the storage according to opts
+// https://tezos.gitlab.io/mainnet/api/rpc.html#post-block-id-context-contracts-contract-id-storage-normalized
+func (s *Service) GetContractStorageWithOptions(ctx context.Context, chainID, blockID, contractID string, opts *NormalizeOptions) (*Micheline, error) {
+	if opts == nil {
+		return s.GetContractStorage(ctx, chainID, blockID, contractID)
+	}
+
+	body := struct {
+		UnparsingMode UnparsingMode `json:"unparsing_mode"`
+	}{
+		UnparsingMode: opts.UnparsingMode,
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/storage/normalized", &body)
+	if err != nil {
+		return nil, err
+	}
+
+	var storage Micheline
+	if err := s.Client.Do(req, &storage); err != nil {
+		return nil, err
+	}
+
+	return &storage, nil
+}
+
+// GetContractScriptWithOptions is like GetContractScript but unparses the code and the storage according to opts
+// https://tezos.gitlab.io/mainnet/api/rpc.html#post-block-id-context-contracts-contract-id-script-normalized
+func (s *Service) GetContractScriptWithOptions(ctx context.Context, chainID, blockID, contractID string, opts *NormalizeOptions) (*ContractScript, error) {
+	if opts == nil {
+		return s.GetContractScript(ctx, chainID, blockID, contractID)
+	}
+
+	body := struct {
+		UnparsingMode  UnparsingMode `json:"unparsing_mode"`
+		NormalizeTypes bool          `json:"normalize_types,omitempty"`
+	}{
+		UnparsingMode:  opts.UnparsingMode,
+		NormalizeTypes: opts.NormalizeTypes,
+	}
+
+	req, err := s.Client.NewRequest(ctx, http.MethodPost, "/chains/"+chainID+"/blocks/"+blockID+"/context/contracts/"+contractID+"/script/normalized", &body)
+	if err != nil {
+		return nil, err
+	}
+
+	var script ContractScript
+	if err := s.Client.Do(req, &script); err != nil {
+		return nil, err
+	}
+
+	return &script, nil
+}
+
+// GetContractStorageTyped returns the contract's storage decoded against its type. The script endpoint returns
+// the storage along with the code so a single request is made. Records become map[string]interface{} keyed
+// by field annotations, or by positions for fields without annotations. See DecodeMichelineValue for details.
+func (s *Service) GetContractStorageTyped(ctx context.Context, chainID, blockID, contractID string) (interface{}, error) {
+	script, err := s.GetContractScript(ctx, chainID, blockID, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := script.StorageType()
+	if err != nil {
+		return nil, err
+	}
+
+	if script.Storage == nil {
+		return nil, fmt.Errorf("tezos: contract %s has no storage", contractID)
+	}
+
+	return DecodeMichelineValue(typ, script.Storage)
+}
+
+func michelineFieldName(typ *Micheline) string {
+	for _, a := range typ.Annots {
+		if strings.HasPrefix(a, "%") {
+			return a[1:]
+		}
+	}
+	return ""
+}
+
+// combArgs returns two components of a pair type or value written in the comb form, i.e. (Pair a b c) or {a; b; c}
+func combArgs(m *Micheline) ([]*Micheline, error) {
+	var args []*Micheline
+	switch {
+	case m.Kind == MichelineSeq:
+		args = m.Seq
+	case m.Kind == MichelinePrim:
+		args = m.Args
+	}
+
+	switch {
+	case len(args) < 2:
+		return nil, fmt.Errorf("tezos: invalid pair: %d components", len(args))
+	case len(args) == 2:
+		return args, nil
+	}
+
+	rest := *m
+	if rest.Kind == MichelineSeq {
+		rest.Seq = args[1:]
+	} else {
+		rest.Args = args[1:]
+		rest.Annots = nil
+	}
+	return []*Micheline{args[0], &rest}, nil
+}
+
+func collectPairFields(typ, val *Micheline, out map[string]interface{}, idx *int) error {
+	types, err := combArgs(typ)
+	if err != nil {
+		return err
+	}
+	if val.Kind == MichelinePrim && val.Prim != "Pair" {
+		return fmt.Errorf("tezos: Pair expected, got %s", val.Prim)
+	}
+	vals, err := combArgs(val)
+	if err != nil {
+		return err
+	}
+
+	for i, t := range types {
+		name := michelineFieldName(t)
+		if name == "" && t.Kind == MichelinePrim && t.Prim == "pair" {
+			// Flatten nested anonymous records
+			if err := collectPairFields(t, vals[i], out, idx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		v, err := DecodeMichelineValue(t, vals[i])
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = strconv.Itoa(*idx)
+		}
+		out[name] = v
+		*idx++
+	}
+
+	return nil
+}
+
+// DecodeMichelineValue converts the Micheline value of the given type into Go types:
+//
+//	pair                        map[string]interface{} keyed by field annotations or positions
+//	or                          map[string]interface{} with a single key, the field annotation or "Left"/"Right"
+//	option                      nil or the value
+//	list, set                   []interface{}
+//	map                         map[string]interface{} keyed by the formatted key
+//	int, nat, mutez, big_map    *big.Int
+//	bool                        bool
+//	unit                        nil
+//	bytes                       HexBytes
+//	string, address, key, etc.  string if the value is a string, HexBytes if it's in the optimized form
+//
+// Other types (e.g. lambda) are returned as *Micheline.
+func DecodeMichelineValue(typ, val *Micheline) (interface{}, error) {
+	if typ == nil || val == nil {
+		return nil, fmt.Errorf("tezos: nil Micheline node")
+	}
+	if typ.Kind != MichelinePrim {
+		return nil, fmt.Errorf("tezos: invalid Micheline type")
+	}
+
+	switch typ.Prim {
+	case "pair":
+		res := make(map[string]interface{})
+		var idx int
+		if err := collectPairFields(typ, val, res, &idx); err != nil {
+			return nil, err
+		}
+		return res, nil
+
+	case "or":
+		if len(typ.Args) != 2 || val.Kind != MichelinePrim || len(val.Args) != 1 {
+			return nil, fmt.Errorf("tezos: invalid or value")
+		}
+		var (
+			t   *Micheline
+			key string
+		)
+		switch val.Prim {
+		case "Left":
+			t, key = typ.Args[0], "Left"
+		case "Right":
+			t, key = typ.Args[1], "Right"
+		default:
+			return nil, fmt.Errorf("tezos: Left or Right expected, got %s", val.Prim)
+		}
+		v, err := DecodeMichelineValue(t, val.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		if name := michelineFieldName(t); name != "" {
+			key = name
+		}
+		return map[string]interface{}{key: v}, nil
+
+	case "option":
+		if len(typ.Args) != 1 || val.Kind != MichelinePrim {
+			return nil, fmt.Errorf("tezos: invalid option value")
+		}
+		switch {
+		case val.Prim == "None":
+			return nil, nil
+		case val.Prim == "Some" && len(val.Args) == 1:
+			return DecodeMichelineValue(typ.Args[0], val.Args[0])
+		}
+		return nil, fmt.Errorf("tezos: Some or None expected, got %s", val.Prim)
+
+	case "list", "set":
+		if len(typ.Args) != 1 || val.Kind != MichelineSeq {
+			return nil, fmt.Errorf("tezos: invalid %s value", typ.Prim)
+		}
+		res := make([]interface{}, len(val.Seq))
+		for i, item := range val.Seq {
+			v, err := DecodeMichelineValue(typ.Args[0], item)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = v
+		}
+		return res, nil
+
+	case "map", "big_map":
+		if val.Kind == MichelineInt && typ.Prim == "big_map" {
+			// Big map id
+			return val.Int, nil
+		}
+		if len(typ.Args) != 2 || val.Kind != MichelineSeq {
+			return nil, fmt.Errorf("tezos: invalid %s value", typ.Prim)
+		}
+		res := make(map[string]interface{}, len(val.Seq))
+		for _, elt := range val.Seq {
+			if elt.Kind != MichelinePrim || elt.Prim != "Elt" || len(elt.Args) != 2 {
+				return nil, fmt.Errorf("tezos: Elt expected")
+			}
+			k, err := DecodeMichelineValue(typ.Args[0], elt.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			v, err := DecodeMichelineValue(typ.Args[1], elt.Args[1])
+			if err != nil {
+				return nil, err
+			}
+			res[fmt.Sprint(k)] = v
+		}
+		return res, nil
+
+	case "int", "nat", "mutez":
+		if val.Kind != MichelineInt {
+			return nil, fmt.Errorf("tezos: invalid %s value", typ.Prim)
+		}
+		return val.Int, nil
+
+	case "bool":
+		if val.Kind == MichelinePrim && (val.Prim == "True" || val.Prim == "False") {
+			return val.Prim == "True", nil
+		}
+		return nil, fmt.Errorf("tezos: invalid bool value")
+
+	case "unit":
+		return nil, nil
+	}
+
+	switch val.Kind {
+	case MichelineString:
+		return val.String, nil
+	case MichelineBytes:
+		return val.Bytes, nil
+	case MichelineInt:
+		// e.g. timestamp
+		return val.Int, nil
+	}
+
+	return val, nil
+}
+
+// findMichelineField walks nested pairs looking for a field with one of the given annotations
+func findMichelineField(typ, val *Micheline, names ...string) (*Micheline, *Micheline, bool) {
+	name := michelineFieldName(typ)
+	for _, n := range names {
+		if name == n {
+			return typ, val, true
+		}
+	}
+
+	if typ.Kind != MichelinePrim || typ.Prim != "pair" {
+		return nil, nil, false
+	}
+	types, err := combArgs(typ)
+	if err != nil {
+		return nil, nil, false
+	}
+	vals, err := combArgs(val)
+	if err != nil {
+		return nil, nil, false
+	}
+	for i, t := range types {
+		if ft, fv, ok := findMichelineField(t, vals[i], names...); ok {
+			return ft, fv, true
+		}
+	}
+	return nil, nil, false
+}
+
+// GetFA12Balance returns the token balance of the owner in the FA1.2 contract. The ledger big map
+// annotated as %ledger or %balances must be keyed by address and hold either the balance
+// or a record with a %balance field. Zero is returned if the owner has no ledger entry.
+func (s *Service) GetFA12Balance(ctx context.Context, chainID, blockID, contractID, owner string) (*BigInt, error) {
+	script, err := s.GetContractScript(ctx, chainID, blockID, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := script.StorageType()
+	if err != nil {
+		return nil, err
+	}
+	if script.Storage == nil {
+		return nil, fmt.Errorf("tezos: contract %s has no storage", contractID)
+	}
+
+	ledgerType, ledger, ok := findMichelineField(typ, script.Storage, "ledger", "balances")
+	if !ok || ledgerType.Prim != "big_map" || len(ledgerType.Args) != 2 ||
+		ledgerType.Args[0].Kind != MichelinePrim || ledgerType.Args[0].Prim != "address" ||
+		ledger.Kind != MichelineInt || !ledger.Int.IsInt64() {
+		return nil, fmt.Errorf("tezos: contract %s doesn't look like FA1.2: address keyed ledger big map not found", contractID)
+	}
+	valueType := ledgerType.Args[1]
+
+	key, err := michelineOptimizedBytes(forgeContractID, owner)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := PackMicheline(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var value Micheline
+	if err := s.getBigMapValue(ctx, chainID, blockID, ledger.Int.Int64(), ScriptExprHash(packed), &value); err != nil {
+		if isHTTPStatus(err, http.StatusNotFound) {
+			return &BigInt{}, nil
+		}
+		return nil, err
+	}
+
+	balanceType, balance := valueType, &value
+	if valueType.Prim != "nat" {
+		if balanceType, balance, ok = findMichelineField(valueType, &value, "balance"); !ok {
+			return nil, fmt.Errorf("tezos: contract %s doesn't look like FA1.2: balance not found in the ledger value", contractID)
+		}
+	}
+	if balanceType.Prim != "nat" || balance.Kind != MichelineInt {
+		return nil, fmt.Errorf("tezos: contract %s doesn't look like FA1.2: balance isn't a nat", contractID)
+	}
+
+	var res BigInt
+	res.Int.Set(balance.Int)
+	return &res, nil
+}
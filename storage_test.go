@@ -0,0 +1,182 @@
+package tezos
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetContractStorageTyped(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/contracts/script.json")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/chains/main/blocks/head/context/contracts/KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv/script", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	storage, err := s.GetContractStorageTyped(context.Background(), "main", "head", "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv")
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"owner":    "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+		"ledger":   big.NewInt(17),
+		"delegate": nil,
+		"metadata": map[string]interface{}{
+			"": HexBytes("tezos-storage:data"),
+		},
+		"paused": false,
+		"5":      big.NewInt(1000000),
+	}
+	require.Equal(t, expected, storage)
+}
+
+func TestDecodeMichelineValue(t *testing.T) {
+	cases := []struct {
+		typ      string
+		val      string
+		expected interface{}
+	}{
+		{
+			typ:      `{"prim":"or","args":[{"prim":"nat","annots":["%count"]},{"prim":"string"}]}`,
+			val:      `{"prim":"Left","args":[{"int":"3"}]}`,
+			expected: map[string]interface{}{"count": big.NewInt(3)},
+		},
+		{
+			typ:      `{"prim":"or","args":[{"prim":"nat","annots":["%count"]},{"prim":"string"}]}`,
+			val:      `{"prim":"Right","args":[{"string":"x"}]}`,
+			expected: map[string]interface{}{"Right": "x"},
+		},
+		{
+			typ:      `{"prim":"list","args":[{"prim":"option","args":[{"prim":"int"}]}]}`,
+			val:      `[{"prim":"Some","args":[{"int":"-1"}]},{"prim":"None"}]`,
+			expected: []interface{}{big.NewInt(-1), nil},
+		},
+		{
+			typ:      `{"prim":"pair","args":[{"prim":"int"},{"prim":"pair","args":[{"prim":"bool"},{"prim":"unit"}]}]}`,
+			val:      `{"prim":"Pair","args":[{"int":"1"},{"prim":"True"},{"prim":"Unit"}]}`,
+			expected: map[string]interface{}{"0": big.NewInt(1), "1": true, "2": nil},
+		},
+	}
+
+	for _, c := range cases {
+		var typ, val Micheline
+		require.NoError(t, typ.UnmarshalJSON([]byte(c.typ)))
+		require.NoError(t, val.UnmarshalJSON([]byte(c.val)))
+
+		v, err := DecodeMichelineValue(&typ, &val)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, v)
+	}
+
+	var typ, val Micheline
+	require.NoError(t, typ.UnmarshalJSON([]byte(`{"prim":"nat"}`)))
+	require.NoError(t, val.UnmarshalJSON([]byte(`{"string":"1"}`)))
+	_, err := DecodeMichelineValue(&typ, &val)
+	require.Error(t, err)
+}
+
+func TestGetFA12Balance(t *testing.T) {
+	script, err := ioutil.ReadFile("fixtures/contracts/fa12_script.json")
+	require.NoError(t, err)
+	value, err := ioutil.ReadFile("fixtures/big_maps/value.json")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/context/contracts/KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH/script":
+			w.Write(script)
+		case "/chains/main/blocks/head/context/big_maps/31/expruH3qgknRBJVLVkwdzf6wfBxd7Y1uqNxr7zuMFxTC12e5PacLfv":
+			w.Write(value)
+		case "/chains/main/blocks/head/context/big_maps/31/exprvRwUfM2eQfjJfQnutTVzL9v22dx549AbgFNHrntwnLbYQcLjac":
+			// Pair written as a sequence
+			w.Write([]byte(`[{"int":"42"},[]]`))
+		case "/chains/main/blocks/head/context/contracts/KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv/script":
+			w.Write([]byte(`{"code":[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"nat","annots":["%counter"]}]},{"prim":"code","args":[[]]}],"storage":{"int":"5"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	balance, err := s.GetFA12Balance(context.Background(), "main", "head", "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.NoError(t, err)
+	require.Equal(t, "1000000", balance.String())
+
+	balance, err = s.GetFA12Balance(context.Background(), "main", "head", "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH", "tz1SfH1vxAt2TTZV7mpsN79uGas5LHhV8epq")
+	require.NoError(t, err)
+	require.Equal(t, "42", balance.String())
+
+	// No ledger entry
+	balance, err = s.GetFA12Balance(context.Background(), "main", "head", "KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH", "tz2BCeQSi5ETyKJsob61pWCoQvoGtsrJBEt2")
+	require.NoError(t, err)
+	require.Equal(t, "0", balance.String())
+
+	// Not an FA1.2 contract
+	_, err = s.GetFA12Balance(context.Background(), "main", "head", "KT1HqTpWpn4tApBWZjzYQB6UofwvW9RB5hLv", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't look like FA1.2")
+}
+
+func TestGetContractStorageWithOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/blocks/head/context/contracts/KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43/storage":
+			require.Equal(t, http.MethodGet, r.Method)
+			w.Write([]byte(`{"bytes":"000002298c03ed7d454a101eb7022bc95f7e5f41ac78"}`))
+		case "/chains/main/blocks/head/context/contracts/KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43/storage/normalized":
+			require.Equal(t, http.MethodPost, r.Method)
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"unparsing_mode":"Readable"}`, string(body))
+			w.Write([]byte(`{"string":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}`))
+		case "/chains/main/blocks/head/context/contracts/KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43/script/normalized":
+			require.Equal(t, http.MethodPost, r.Method)
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"unparsing_mode":"Readable","normalize_types":true}`, string(body))
+			w.Write([]byte(`{"code":[{"prim":"storage","args":[{"prim":"address"}]}],"storage":{"string":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"}}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	ctx := context.Background()
+
+	storage, err := s.GetContractStorageWithOptions(ctx, "main", "head", "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", nil)
+	require.NoError(t, err)
+	require.Equal(t, MichelineBytes, storage.Kind)
+
+	storage, err = s.GetContractStorageWithOptions(ctx, "main", "head", "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", &NormalizeOptions{UnparsingMode: UnparsingReadable})
+	require.NoError(t, err)
+	require.Equal(t, MichelineString, storage.Kind)
+	require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", storage.String)
+
+	script, err := s.GetContractScriptWithOptions(ctx, "main", "head", "KT1DUfaMfTRZZkvZAYQT5b3byXnvqoAykc43", &NormalizeOptions{UnparsingMode: UnparsingReadable, NormalizeTypes: true})
+	require.NoError(t, err)
+	require.Equal(t, "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", script.Storage.String)
+}
@@ -1,11 +1,13 @@
 package tezos
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
+	"sync"
 
 	"github.com/davecgh/go-spew/spew"
 	log "github.com/sirupsen/logrus"
@@ -68,6 +70,55 @@ func unmarshalInSlice(data []byte, s []interface{}) error {
 	return nil
 }
 
+// forEachConcurrently calls fn for each index in [0, n) using up to concurrency goroutines.
+// The first error cancels the context passed to fn and is returned.
+func forEachConcurrently(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	c, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		indices = make(chan int)
+		errs    = make(chan error, concurrency)
+	)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := fn(c, i); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+feedLoop:
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-c.Done():
+			break feedLoop
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
 func isLevelEnabled(logger Logger, level log.Level) bool {
 	switch l := logger.(type) {
 	case *log.Entry:
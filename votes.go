@@ -6,6 +6,11 @@ type Ballot struct {
 	Ballot string `json:"ballot" yaml:"ballot"`
 }
 
+// Vote returns the ballot value
+func (b *Ballot) Vote() BallotVote {
+	return BallotVote(b.Ballot)
+}
+
 // BallotListing holds information about a Tezos delegate and his voting weight in rolls
 type BallotListing struct {
 	PKH   string `json:"pkh" yaml:"pkh"`
@@ -25,25 +30,86 @@ type Proposal struct {
 	SupporterCount int
 }
 
+// VotingPeriodKind is a kind of the voting period. Some kinds were renamed in Edo, both names are accepted.
+type VotingPeriodKind string
+
+// Voting period kinds
+const (
+	VotingPeriodProposal VotingPeriodKind = "proposal"
+	// VotingPeriodExploration is called VotingPeriodTestingVote before Edo
+	VotingPeriodExploration VotingPeriodKind = "exploration"
+	VotingPeriodTestingVote VotingPeriodKind = "testing_vote"
+	// VotingPeriodCooldown is called VotingPeriodTesting before Edo
+	VotingPeriodCooldown VotingPeriodKind = "cooldown"
+	VotingPeriodTesting  VotingPeriodKind = "testing"
+	// VotingPeriodPromotion is called VotingPeriodPromotionVote before Edo
+	VotingPeriodPromotion     VotingPeriodKind = "promotion"
+	VotingPeriodPromotionVote VotingPeriodKind = "promotion_vote"
+	// VotingPeriodAdoption is introduced in Edo
+	VotingPeriodAdoption VotingPeriodKind = "adoption"
+)
+
 // PeriodKind contains information about tezos voting period kind
-type PeriodKind string
+type PeriodKind = VotingPeriodKind
+
+// Canonical returns the current name of the period kind
+func (p VotingPeriodKind) Canonical() VotingPeriodKind {
+	switch p {
+	case VotingPeriodTestingVote:
+		return VotingPeriodExploration
+	case VotingPeriodTesting:
+		return VotingPeriodCooldown
+	case VotingPeriodPromotionVote:
+		return VotingPeriodPromotion
+	}
+	return p
+}
+
+// Valid returns true if the period kind is known
+func (p VotingPeriodKind) Valid() bool {
+	switch p.Canonical() {
+	case VotingPeriodProposal, VotingPeriodExploration, VotingPeriodCooldown, VotingPeriodPromotion, VotingPeriodAdoption:
+		return true
+	}
+	return false
+}
 
 // IsProposal return true if period kind is proposal
-func (p PeriodKind) IsProposal() bool {
-	return p == "proposal"
+func (p VotingPeriodKind) IsProposal() bool {
+	return p == VotingPeriodProposal
+}
+
+// IsTestingVote return true if period kind is testing vote (exploration)
+func (p VotingPeriodKind) IsTestingVote() bool {
+	return p.Canonical() == VotingPeriodExploration
 }
 
-// IsTestingVote return true if period kind is testing vote
-func (p PeriodKind) IsTestingVote() bool {
-	return p == "testing_vote"
+// IsTesting return true if period kind is testing (cooldown)
+func (p VotingPeriodKind) IsTesting() bool {
+	return p.Canonical() == VotingPeriodCooldown
 }
 
-// IsTesting return true if period kind is testing
-func (p PeriodKind) IsTesting() bool {
-	return p == "testing"
+// IsPromotionVote true if period kind is promotion vote (promotion)
+func (p VotingPeriodKind) IsPromotionVote() bool {
+	return p.Canonical() == VotingPeriodPromotion
 }
 
-// IsPromotionVote true if period kind is promotion vote
-func (p PeriodKind) IsPromotionVote() bool {
-	return p == "promotion_vote"
+// IsAdoption true if period kind is adoption
+func (p VotingPeriodKind) IsAdoption() bool {
+	return p == VotingPeriodAdoption
+}
+
+// BallotVote is a value of the ballot
+type BallotVote string
+
+// Ballot values
+const (
+	BallotYay  BallotVote = "yay"
+	BallotNay  BallotVote = "nay"
+	BallotPass BallotVote = "pass"
+)
+
+// Valid returns true if the ballot value is known
+func (b BallotVote) Valid() bool {
+	return b == BallotYay || b == BallotNay || b == BallotPass
 }
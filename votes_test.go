@@ -0,0 +1,58 @@
+package tezos
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVotingPeriodKind(t *testing.T) {
+	cases := []struct {
+		kind      VotingPeriodKind
+		canonical VotingPeriodKind
+		valid     bool
+	}{
+		{kind: "proposal", canonical: VotingPeriodProposal, valid: true},
+		{kind: "testing_vote", canonical: VotingPeriodExploration, valid: true},
+		{kind: "exploration", canonical: VotingPeriodExploration, valid: true},
+		{kind: "testing", canonical: VotingPeriodCooldown, valid: true},
+		{kind: "cooldown", canonical: VotingPeriodCooldown, valid: true},
+		{kind: "promotion_vote", canonical: VotingPeriodPromotion, valid: true},
+		{kind: "promotion", canonical: VotingPeriodPromotion, valid: true},
+		{kind: "adoption", canonical: VotingPeriodAdoption, valid: true},
+		{kind: "unknown", canonical: "unknown", valid: false},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.canonical, c.kind.Canonical(), c.kind)
+		require.Equal(t, c.valid, c.kind.Valid(), c.kind)
+	}
+
+	require.True(t, VotingPeriodTestingVote.IsTestingVote())
+	require.True(t, VotingPeriodExploration.IsTestingVote())
+	require.True(t, VotingPeriodTesting.IsTesting())
+	require.True(t, VotingPeriodCooldown.IsTesting())
+	require.True(t, VotingPeriodPromotionVote.IsPromotionVote())
+	require.True(t, VotingPeriodPromotion.IsPromotionVote())
+	require.False(t, VotingPeriodProposal.IsPromotionVote())
+}
+
+func TestBallotVote(t *testing.T) {
+	for _, b := range []BallotVote{BallotYay, BallotNay, BallotPass} {
+		require.True(t, b.Valid(), b)
+	}
+	require.False(t, BallotVote("maybe").Valid())
+
+	var el BallotOperationElem
+	require.NoError(t, json.Unmarshal([]byte(`{"kind": "ballot", "ballot": "nay"}`), &el))
+	require.Equal(t, "nay", el.Ballot)
+	require.Equal(t, BallotNay, el.Vote())
+
+	b := Ballot{Ballot: "yay"}
+	require.True(t, b.Vote().Valid())
+
+	var m BlockHeaderMetadata
+	require.NoError(t, json.Unmarshal([]byte(`{"voting_period_kind": "testing_vote"}`), &m))
+	require.Equal(t, VotingPeriodExploration, m.PeriodKind().Canonical())
+}
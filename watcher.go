@@ -0,0 +1,254 @@
+package tezos
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultBlockWatcherDepth is the default number of recent headers tracked by BlockWatcher
+const DefaultBlockWatcherDepth = 64
+
+// BlockEvent is emitted by BlockWatcher for every new head
+type BlockEvent struct {
+	Header  *BlockInfo
+	IsReorg bool
+	// ForkDepth is the number of previously emitted blocks abandoned by the reorganization.
+	// -1 means the common ancestor is beyond the tracked history.
+	ForkDepth int
+}
+
+// BlockWatcher monitors new heads and detects chain reorganizations
+type BlockWatcher struct {
+	Service *Service
+	ChainID string
+	// Depth is the number of recent headers used to determine the fork depth. Zero means DefaultBlockWatcherDepth.
+	Depth int
+
+	history []*BlockInfo
+}
+
+func (w *BlockWatcher) depth() int {
+	if w.Depth > 0 {
+		return w.Depth
+	}
+	return DefaultBlockWatcherDepth
+}
+
+// push returns nil if the block was seen already
+func (w *BlockWatcher) push(b *BlockInfo) *BlockEvent {
+	for _, h := range w.history {
+		if h.Hash == b.Hash {
+			return nil
+		}
+	}
+
+	ev := BlockEvent{Header: b}
+	if len(w.history) != 0 && w.history[len(w.history)-1].Hash != b.Predecessor {
+		ev.IsReorg = true
+		ev.ForkDepth = -1
+		var i int
+		for i = len(w.history) - 1; i >= 0; i-- {
+			if w.history[i].Hash == b.Predecessor {
+				ev.ForkDepth = len(w.history) - 1 - i
+				break
+			}
+		}
+		// i is -1 if the common ancestor wasn't found
+		w.history = w.history[:i+1]
+	}
+
+	w.history = append(w.history, b)
+	if d := w.depth(); len(w.history) > d {
+		w.history = append(w.history[:0], w.history[len(w.history)-d:]...)
+	}
+
+	return &ev
+}
+
+// Watch monitors new heads and sends events to the channel until the context is canceled or an error occurs
+func (w *BlockWatcher) Watch(ctx context.Context, results chan<- *BlockEvent) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	heads := make(chan *BlockInfo, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- w.Service.MonitorHeads(ctx, w.ChainID, heads)
+		close(heads)
+	}()
+
+	for b := range heads {
+		ev := w.push(b)
+		if ev == nil {
+			continue
+		}
+
+		select {
+		case results <- ev:
+		case <-ctx.Done():
+			// Drain
+			for range heads {
+			}
+			<-errCh
+			return ctx.Err()
+		}
+	}
+
+	return <-errCh
+}
+
+const (
+	reconnectMinDelay = 100 * time.Millisecond
+	reconnectMaxDelay = 10 * time.Second
+)
+
+func isPermanentMonitorError(err error) bool {
+	if e, ok := err.(HTTPStatus); ok {
+		return e.StatusCode()/100 == 4
+	}
+	return false
+}
+
+// MonitorWithReconnect is like MonitorHeads but re-establishes the stream with an exponential backoff
+// if the connection drops. The current head sent by the node after reconnection is skipped
+// if it was delivered already. Returns when the context is canceled or the node replies with 4xx.
+func (s *Service) MonitorWithReconnect(ctx context.Context, chainID string, results chan<- *BlockInfo) error {
+	var last string
+	delay := reconnectMinDelay
+
+	for {
+		heads := make(chan *BlockInfo, 100)
+		errCh := make(chan error, 1)
+
+		go func() {
+			errCh <- s.MonitorHeads(ctx, chainID, heads)
+			close(heads)
+		}()
+
+		for b := range heads {
+			delay = reconnectMinDelay
+			if b.Hash == last {
+				continue
+			}
+			last = b.Hash
+
+			select {
+			case results <- b:
+			case <-ctx.Done():
+				// Drain
+				for range heads {
+				}
+				<-errCh
+				return ctx.Err()
+			}
+		}
+
+		err := <-errCh
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if isPermanentMonitorError(err) {
+			return err
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+
+		if delay *= 2; delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
+
+// WatchBalance monitors new heads and sends the contract's balance to the channel every time it changes.
+// The balance at the first received head is always sent. Returns when the context is canceled or an error occurs.
+func (s *Service) WatchBalance(ctx context.Context, chainID, contractID string, out chan<- *BigInt) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	heads := make(chan *BlockInfo, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- s.MonitorHeads(ctx, chainID, heads)
+		close(heads)
+	}()
+
+	stop := func(err error) error {
+		cancel()
+		// Drain
+		for range heads {
+		}
+		<-errCh
+		return err
+	}
+
+	var last *BigInt
+	for b := range heads {
+		balance, err := s.GetContractBalance(ctx, chainID, b.Hash, contractID)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return stop(err)
+		}
+
+		v := &BigInt{Int: *balance}
+		if last != nil && last.Cmp(v) == 0 {
+			continue
+		}
+		last = v
+
+		select {
+		case out <- v:
+		case <-ctx.Done():
+			return stop(ctx.Err())
+		}
+	}
+
+	return <-errCh
+}
+
+// MaxCommonAncestorDepth is the maximum number of predecessors visited by FindCommonAncestor
+const MaxCommonAncestorDepth = 1000
+
+// FindCommonAncestor returns the hash of the latest block both blocks descend from (or are equal to)
+// by walking their predecessors. An error is returned if it's deeper than MaxCommonAncestorDepth.
+func (s *Service) FindCommonAncestor(ctx context.Context, chainID, hashA, hashB string) (string, error) {
+	a, err := s.GetBlockHeader(ctx, chainID, hashA)
+	if err != nil {
+		return "", err
+	}
+	b, err := s.GetBlockHeader(ctx, chainID, hashB)
+	if err != nil {
+		return "", err
+	}
+
+	for steps := 0; a.Hash != b.Hash; steps++ {
+		if steps == MaxCommonAncestorDepth {
+			return "", fmt.Errorf("tezos: no common ancestor of %s and %s within %d blocks", hashA, hashB, MaxCommonAncestorDepth)
+		}
+
+		// Step back the higher one or both if they are at the same level
+		stepA, stepB := a.Level >= b.Level, b.Level >= a.Level
+		if stepA {
+			if a, err = s.GetBlockHeader(ctx, chainID, a.Predecessor); err != nil {
+				return "", err
+			}
+		}
+		if stepB {
+			if b, err = s.GetBlockHeader(ctx, chainID, b.Predecessor); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return a.Hash, nil
+}
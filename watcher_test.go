@@ -0,0 +1,243 @@
+package tezos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockWatcher(t *testing.T) {
+	blk := func(hash, pred string, level int) *BlockInfo {
+		return &BlockInfo{Hash: hash, Predecessor: pred, Level: level}
+	}
+
+	t.Run("Clean", func(t *testing.T) {
+		var w BlockWatcher
+		for i, b := range []*BlockInfo{
+			blk("B1", "B0", 1),
+			blk("B2", "B1", 2),
+			blk("B3", "B2", 3),
+		} {
+			ev := w.push(b)
+			require.NotNil(t, ev, i)
+			require.Equal(t, &BlockEvent{Header: b}, ev)
+		}
+		// Duplicate
+		require.Nil(t, w.push(blk("B3", "B2", 3)))
+	})
+
+	t.Run("Reorg", func(t *testing.T) {
+		var w BlockWatcher
+		for _, b := range []*BlockInfo{
+			blk("B1", "B0", 1),
+			blk("B2", "B1", 2),
+			blk("B3", "B2", 3),
+		} {
+			require.False(t, w.push(b).IsReorg)
+		}
+
+		// B2 and B3 are abandoned
+		ev := w.push(blk("B2'", "B1", 2))
+		require.True(t, ev.IsReorg)
+		require.Equal(t, 2, ev.ForkDepth)
+
+		ev = w.push(blk("B3'", "B2'", 3))
+		require.False(t, ev.IsReorg)
+		ev = w.push(blk("B4'", "B3'", 4))
+		require.False(t, ev.IsReorg)
+	})
+
+	t.Run("Deep", func(t *testing.T) {
+		w := BlockWatcher{Depth: 2}
+		w.push(blk("B1", "B0", 1))
+		w.push(blk("B2", "B1", 2))
+		w.push(blk("B3", "B2", 3))
+
+		ev := w.push(blk("B2'", "B1", 2))
+		require.True(t, ev.IsReorg)
+		require.Equal(t, -1, ev.ForkDepth)
+	})
+}
+
+func TestBlockWatcherWatch(t *testing.T) {
+	heads := []*BlockInfo{
+		&BlockInfo{Hash: "B1", Predecessor: "B0", Level: 1},
+		&BlockInfo{Hash: "B2", Predecessor: "B1", Level: 2},
+		&BlockInfo{Hash: "B2'", Predecessor: "B1", Level: 2},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/monitor/heads/main", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		for _, h := range heads {
+			require.NoError(t, enc.Encode(h))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+
+	w := BlockWatcher{Service: &Service{Client: c}, ChainID: "main"}
+	ch := make(chan *BlockEvent, 100)
+	require.NoError(t, w.Watch(context.Background(), ch))
+	close(ch)
+
+	var events []*BlockEvent
+	for ev := range ch {
+		events = append(events, ev)
+	}
+	require.Len(t, events, 3)
+	require.False(t, events[1].IsReorg)
+	require.True(t, events[2].IsReorg)
+	require.Equal(t, 1, events[2].ForkDepth)
+}
+
+func TestMonitorWithReconnect(t *testing.T) {
+	var conn int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/monitor/heads/main", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+
+		if atomic.AddInt32(&conn, 1) == 1 {
+			require.NoError(t, enc.Encode(&BlockInfo{Hash: "B1", Level: 1}))
+			require.NoError(t, enc.Encode(&BlockInfo{Hash: "B2", Level: 2}))
+			w.(http.Flusher).Flush()
+			// Drop the connection mid-stream
+			c, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			c.Close()
+			return
+		}
+
+		// The node sends the current head first
+		require.NoError(t, enc.Encode(&BlockInfo{Hash: "B2", Level: 2}))
+		require.NoError(t, enc.Encode(&BlockInfo{Hash: "B3", Level: 3}))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan *BlockInfo)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.MonitorWithReconnect(ctx, "main", ch)
+	}()
+
+	var hashes []string
+	for len(hashes) < 3 {
+		hashes = append(hashes, (<-ch).Hash)
+	}
+	cancel()
+
+	require.Equal(t, []string{"B1", "B2", "B3"}, hashes)
+	require.Equal(t, context.Canceled, <-errCh)
+	require.Equal(t, int32(2), atomic.LoadInt32(&conn))
+}
+
+func TestFindCommonAncestor(t *testing.T) {
+	// B1 <- B2 <- B3 <- B4
+	//          \
+	//           B3' <- B4' <- B5'
+	headers := map[string]*BlockHeader{}
+	for _, h := range []struct {
+		hash, pred string
+		level      int
+	}{
+		{"B1", "B0", 1},
+		{"B2", "B1", 2},
+		{"B3", "B2", 3},
+		{"B4", "B3", 4},
+		{"B3'", "B2", 3},
+		{"B4'", "B3'", 4},
+		{"B5'", "B4'", 5},
+	} {
+		headers[h.hash] = &BlockHeader{Hash: h.hash, RawBlockHeader: RawBlockHeader{Level: h.level, Predecessor: h.pred}}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hash := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/chains/main/blocks/"), "/header")
+		h, ok := headers[hash]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(h))
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	for _, tc := range [][3]string{
+		{"B4", "B5'", "B2"},
+		{"B5'", "B4", "B2"},
+		{"B4", "B2", "B2"},
+		{"B4", "B4", "B4"},
+		{"B3'", "B3", "B2"},
+	} {
+		ancestor, err := s.FindCommonAncestor(context.Background(), "main", tc[0], tc[1])
+		require.NoError(t, err, tc)
+		require.Equal(t, tc[2], ancestor, tc)
+	}
+
+	_, err = s.FindCommonAncestor(context.Background(), "main", "B4", "B0")
+	require.Error(t, err)
+}
+
+func TestWatchBalance(t *testing.T) {
+	balances := map[string]string{
+		"B1": "100",
+		"B2": "100",
+		"B3": "250",
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/monitor/heads/main" {
+			enc := json.NewEncoder(w)
+			for _, h := range []string{"B1", "B2", "B3"} {
+				require.NoError(t, enc.Encode(&BlockInfo{Hash: h}))
+			}
+			return
+		}
+
+		// /chains/main/blocks/<hash>/context/contracts/<id>/balance
+		parts := strings.Split(r.URL.Path, "/")
+		require.Len(t, parts, 9)
+		require.Equal(t, "/chains/main/blocks/"+parts[4]+"/context/contracts/tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx/balance", r.URL.Path)
+		json.NewEncoder(w).Encode(balances[parts[4]])
+	}))
+	defer srv.Close()
+
+	c, err := NewRPCClient(srv.URL)
+	require.NoError(t, err)
+	s := &Service{Client: c}
+
+	ch := make(chan *BigInt, 100)
+	require.NoError(t, s.WatchBalance(context.Background(), "main", "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx", ch))
+	close(ch)
+
+	var res []string
+	for v := range ch {
+		res = append(res, v.String())
+	}
+	require.Equal(t, []string{"100", "250"}, res)
+}
@@ -0,0 +1,91 @@
+package tezos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// WorkerStatus is the lifecycle phase of a node worker
+type WorkerStatus struct {
+	Phase string    `json:"phase"`
+	Since time.Time `json:"since"`
+}
+
+// WorkerInformation holds statistics reported by a worker
+type WorkerInformation struct {
+	InstancesNumber int           `json:"instances_number"`
+	WStatus         *WorkerStatus `json:"wstatus,omitempty"`
+	QueueLength     int           `json:"queue_length"`
+}
+
+// Worker is an element of a per-chain worker list
+type Worker struct {
+	ChainID     string             `json:"chain_id"`
+	Status      WorkerStatus       `json:"status"`
+	Information *WorkerInformation `json:"information,omitempty"`
+}
+
+// WorkerRequest is a request queued or being processed by a worker
+type WorkerRequest struct {
+	Pushed  time.Time       `json:"pushed"`
+	Request json.RawMessage `json:"request"`
+}
+
+// WorkerState is a detailed state of a single worker
+type WorkerState struct {
+	Status          WorkerStatus     `json:"status"`
+	PendingRequests []*WorkerRequest `json:"pending_requests"`
+	CurrentRequest  *WorkerRequest   `json:"current_request,omitempty"`
+}
+
+func (s *Service) getWorkers(ctx context.Context, path string) ([]*Worker, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var workers []*Worker
+	if err = s.Client.Do(req, &workers); err != nil {
+		return nil, err
+	}
+	return workers, err
+}
+
+func (s *Service) getWorkerState(ctx context.Context, path string) (*WorkerState, error) {
+	req, err := s.Client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var state WorkerState
+	if err = s.Client.Do(req, &state); err != nil {
+		return nil, err
+	}
+	return &state, err
+}
+
+// GetWorkersPrevalidators returns the list of prevalidator workers, one per chain.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-workers-prevalidators
+func (s *Service) GetWorkersPrevalidators(ctx context.Context) ([]*Worker, error) {
+	return s.getWorkers(ctx, "/workers/prevalidators")
+}
+
+// GetWorkersPrevalidator returns the state of the prevalidator worker of the given chain including the request being processed.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-workers-prevalidators-chain-id
+func (s *Service) GetWorkersPrevalidator(ctx context.Context, chainID string) (*WorkerState, error) {
+	return s.getWorkerState(ctx, "/workers/prevalidators/"+chainID)
+}
+
+// GetWorkersChainValidators returns the list of chain validator workers.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-workers-chain-validators
+func (s *Service) GetWorkersChainValidators(ctx context.Context) ([]*Worker, error) {
+	return s.getWorkers(ctx, "/workers/chain_validators")
+}
+
+// GetWorkersChainValidator returns the state of the validator worker of the given chain including the request being processed.
+// https://tezos.gitlab.io/mainnet/api/rpc.html#get-workers-chain-validators-chain-id
+func (s *Service) GetWorkersChainValidator(ctx context.Context, chainID string) (*WorkerState, error) {
+	return s.getWorkerState(ctx, "/workers/chain_validators/"+chainID)
+}